@@ -7,7 +7,10 @@ package main
 
 import (
 	"math"
+	"math/rand"
 	"time"
+
+	"github.com/gazed/vu"
 )
 
 // Animation is a programatically controlled cut scene.
@@ -30,6 +33,11 @@ type animation struct {
 	next     Animation       // a followup animation.
 }
 
+// maxAnimStep is the most an animation advances in one Run, about one
+// frame at 30fps. This keeps a frame hitch from skipping short animations,
+// ie: the cards in an auto move chain.
+const maxAnimStep = 33 * time.Millisecond
+
 // Run implements the Animation interface.
 func (a *animation) Run(delta time.Duration) Animation {
 	if a == nil {
@@ -40,7 +48,7 @@ func (a *animation) Run(delta time.Duration) Animation {
 	}
 
 	// run animation
-	a.elapsed += delta
+	a.elapsed += min(delta, maxAnimStep)
 	fract := min(1.0, float64(a.elapsed)/float64(a.duration))
 	if a.elapsed < a.duration {
 		if a.during != nil {
@@ -69,78 +77,400 @@ type move struct {
 	to   uint
 }
 
+// boardMoves returns the cards that moved between two board positions.
+func boardMoves(prev, board [52]uint) map[uint]move {
+	moves := map[uint]move{}
+	for i, bid := range board {
+		cid := uint(i)
+		switch {
+		case bid >= HIDDEN_CARD:
+			// don't animate existing foundation cards during gameplay.
+		case prev[cid] >= HIDDEN_CARD && bid == prev[cid]-HIDDEN_CARD:
+			// foundation card uncovered by an undo has not moved.
+		case prev[cid] >= HIDDEN_CARD:
+			// animate foundation cards when changing to new game.
+			moves[cid] = move{
+				from: prev[cid] - HIDDEN_CARD,
+				to:   bid,
+			}
+		case bid != prev[cid]:
+			// regular card move
+			moves[cid] = move{
+				from: prev[cid],
+				to:   bid,
+			}
+		}
+	}
+	return moves
+}
+
+// movedCards returns the moving cards, in card order, that are
+// highlighted by a move effect, ie: a ghost at the starting location
+// or a pulse at the end location. No cards are returned if disabled.
+func movedCards(moves map[uint]move, enabled bool) (cards []uint) {
+	if !enabled {
+		return cards
+	}
+	for cid := AC; cid <= KS; cid++ {
+		if _, ok := moves[cid]; ok {
+			cards = append(cards, cid)
+		}
+	}
+	return cards
+}
+
 // move one or more cards from one board position to another,
 // ie: move a group of cards in the cascade to a new board position.
 func animateCardMoves(gm *game, from [52]uint) Animation {
-	a := &animation{elapsed: 0, duration: 200 * time.Millisecond, next: nil}
+	return animateBoardChange(gm, from, true)
+}
+
+// animateUndo moves the undone cards back to their previous positions.
+// Unlike forward moves, cards are not auto moved afterwards.
+func animateUndo(gm *game, from [52]uint) Animation {
+	return animateBoardChange(gm, from, false)
+}
+
+// animateBoardChange moves the cards that differ between the from board
+// and the current board. Cards are auto moved afterwards if autoMove
+// is true and the player preferences allow it.
+func animateBoardChange(gm *game, from [52]uint, autoMove bool) Animation {
+	travel := moveDistance(boardMoves(from, gm.logic.board))
+	minimum := time.Duration(gm.save.MinMoveTime) * time.Millisecond
+	a := &animation{elapsed: 0, duration: moveDuration(travel, minimum), next: nil}
+	if gm.save.ReduceMotion {
+		a.duration = 0 // cards jump to their new positions.
+	}
 
 	// on start: find out which cards have moved.
 	prev := from // copy array by value.
 	moves := map[uint]move{}
+	ghosts := []uint{}
 	a.intro = func() {
-		for i, bid := range gm.logic.board {
-			cid := uint(i)
-			switch {
-			case bid >= HIDDEN_CARD:
-				// don't animate existing foundation cards during gameplay.
-			case prev[cid] >= HIDDEN_CARD && bid != prev[cid]:
-				// animate foundation cards when changing to new game.
-				moves[cid] = move{
-					from: prev[cid] - HIDDEN_CARD,
-					to:   bid,
-				}
-			case bid != prev[cid]:
-				// regular card move
-				moves[cid] = move{
-					from: prev[cid],
-					to:   bid,
+		gm.clearTrails() // from an animation that was replaced.
+		moves = boardMoves(prev, gm.logic.board)
+		for cid := range moves {
+			gm.hideShadow(cid)
+		}
+
+		// optional fading trails behind the moving cards, within budget.
+		if budget := gm.trailBudget(); budget > 0 && len(moves) > 0 {
+			segments := min(maxTrailSegments, budget/len(moves))
+			gm.trails = map[uint][]*vu.Entity{}
+			for cid := range moves {
+				for range segments {
+					quad := gm.scene.AddModel("shd:card", "msh:card", "tex:color:card52")
+					gm.trails[cid] = append(gm.trails[cid], quad)
 				}
 			}
 		}
+
+		// show ghosts at the starting locations, but not for new deals.
+		enabled := gm.save.MoveGhosts && !gm.save.ReduceMotion && gm.logic.MoveNumber() > 0
+		ghosts = movedCards(moves, enabled)
+		for _, cid := range ghosts {
+			x, y, z := placePile(moves[cid].from)
+			gm.ghosts[cid].SetAt(x, y, z)
+			gm.ghosts[cid].Cull(false)
+			gm.ghosted[cid] = true
+		}
 	}
 
 	// during: move the cards from a to b.
 	a.during = func(t float64) {
 
-		// used to lift the card above the other cards while moving.
-		sint := math.Sin(t * math.Pi) // 0 to 1.0 back to 0
-		lift := 0.05 + 0.3*sint
-
 		// move each card that changed.
 		for cid, move := range moves {
-			sax, say, saz := placeCard(move.from)
-			sbx, sby, sbz := placeCard(move.to)
-			sx := lerp(sax, sbx, t)
-			sy := lerp(say, sby, t)
-			sz := lerp(saz, sbz, t) + lift
-			gm.cards[cid].SetAt(sx, sy, sz)
+			gm.cards[cid].SetAt(movePosition(move, t))
+			gm.drawn[cid].valid = false // redraw the card at the end.
+			if gm.save.TiltCards {
+				ta := cardTilt(move.from, true)
+				tb := cardTilt(move.to, true)
+				gm.cards[cid].SetAa(1, 0, 0, lerp(ta, tb, t))
+			}
+		}
+		for cid, quads := range gm.trails {
+			for i, seg := range trailSegments(moves[cid], t, len(quads)) {
+				scale := cardScale * seg.fade
+				quads[i].SetAt(seg.x, seg.y, seg.z).SetScale(scale, scale, 0)
+				quads[i].SetColor(1, 1, 1, seg.fade)
+			}
 		}
 	}
 
 	// on end: redraw the latest board.
 	a.outro = func() {
-		gm.redrawBoard()
+		gm.redrawBoard() // also hides the ghosts.
+		gm.clearTrails()
+
+		// briefly highlight the moved cards, but not for new deals.
+		enabled := !gm.save.ReduceMotion && gm.logic.MoveNumber() > 0
+		gm.startPulse(append(movedCards(moves, enabled), gm.safeHints(from, enabled)...))
+		if !autoMove {
+			return
+		}
 
-		// check if any cards can be auto moved to the foundation.
-		// if so, then immediately run as the next animation.
-		if gm.logic.AutoMoveCard() {
+		// skip the auto move chain when the game is certain to be won.
+		if gm.save.AutoPlay == autoPlayOnMove && gm.autoComplete() {
+			gm.redrawBoard()
+			return
+		}
+
+		// continue a foundation move up the suit, or check if any cards
+		// can be auto moved to the foundation. If so, then immediately
+		// run as the next animation.
+		if gm.continueChain() || gm.autoMoveAfterMove() {
+			duration := autoMoveDuration(a.duration, gm.save.AutoMoveDelay)
+			if duration <= 0 || gm.save.ReduceMotion {
+				// instant: finish the chain and all the auto moves without animating.
+				gm.finishChain()
+				if gm.save.AutoPlay == autoPlayOnMove && gm.tutor == nil {
+					gm.record("autoAll", 0)
+					gm.logic.AutoMoveAll()
+				}
+				gm.redrawBoard()
+				return
+			}
 			gm.updateInfo()
 			a.next = animateCardMoves(gm, gm.logic.PreviousBoard())
+			a.next.(*animation).duration = duration
+		}
+	}
+	return a
+}
+
+// Card moves take maxMoveDuration when a card travels fullMoveDistance
+// or further, and are quicker for shorter moves.
+const (
+	maxMoveDuration  = 200 * time.Millisecond
+	fullMoveDistance = 3.0 // world units, about four cascades.
+)
+
+// moveDistance returns the furthest distance travelled by the moving cards.
+func moveDistance(moves map[uint]move) (furthest float64) {
+	for _, m := range moves {
+		ax, ay, _ := placeCard(m.from)
+		bx, by, _ := placeCard(m.to)
+		furthest = max(furthest, math.Hypot(bx-ax, by-ay))
+	}
+	return furthest
+}
 
-			// speed up sequential moves.
-			an := a.next.(*animation)
-			maxspeed := 90 * time.Millisecond
-			slowdown := time.Duration(float64(a.duration) * 0.80)
-			an.duration = max(maxspeed, slowdown)
+// moveDuration returns the animation time for cards that travel the
+// given distance. The time scales from the player preferred minimum
+// for the shortest moves up to maxMoveDuration for long moves.
+func moveDuration(distance float64, minimum time.Duration) time.Duration {
+	minimum = min(max(minimum, 0), maxMoveDuration)
+	ratio := min(1.0, max(0.0, distance/fullMoveDistance))
+	return minimum + time.Duration(ratio*float64(maxMoveDuration-minimum))
+}
+
+// movePosition returns where a moving card is drawn when it is t of the
+// way along its move. The card is lifted above the other cards while
+// moving.
+func movePosition(m move, t float64) (x, y, z float64) {
+	sint := math.Sin(t * math.Pi) // 0 to 1.0 back to 0
+	lift := 0.05 + 0.3*sint
+	ax, ay, az := placeCard(m.from)
+	bx, by, bz := placeCard(m.to)
+	return lerp(ax, bx, t), lerp(ay, by, t), lerp(az, bz, t) + lift
+}
+
+// card trail quads are spaced along the card path by trailLag of the
+// move time. Each moving card has at most maxTrailSegments quads.
+const (
+	trailLag         = 0.06
+	maxTrailSegments = 6
+)
+
+// trailSegment is one fading quad in the trail behind a moving card.
+type trailSegment struct {
+	x, y, z float64 // location along the card path.
+	fade    float64 // from near 1 behind the card towards 0 at the tail.
+}
+
+// trailSegments returns the trail behind a card that is t of the way
+// along its move. Each segment lags further behind the card and is more
+// faded, and is drawn under the newer segments. The segments bunch up at
+// the start of the move.
+func trailSegments(m move, t float64, segments int) (trail []trailSegment) {
+	for i := 1; i <= segments; i++ {
+		x, y, z := movePosition(m, max(0, t-float64(i)*trailLag))
+		fade := 1 - float64(i)/float64(segments+1)
+		trail = append(trail, trailSegment{x, y, z - 0.001*float64(i), fade})
+	}
+	return trail
+}
+
+// clearTrails removes the trail quads of the card move animation. This
+// is done when the animation ends, or when it is replaced before ending.
+func (gm *game) clearTrails() {
+	for _, quads := range gm.trails {
+		for _, quad := range quads {
+			quad.Dispose(gm.eng)
+		}
+	}
+	gm.trails = nil
+}
+
+// trailBudget returns the most trail quads for a card move animation.
+// Trails are purely cosmetic and are not shown when motion is reduced
+// or for new deals.
+func (gm *game) trailBudget() int {
+	if gm.save.ReduceMotion || gm.logic.MoveNumber() == 0 {
+		return 0
+	}
+	return max(0, gm.save.TrailBudget)
+}
+
+// deal the cards one by one from a deck above the board to their
+// cascade positions, in the same order as the shuffle deals them.
+func animateDeal(gm *game) Animation {
+	a := &animation{elapsed: 0, duration: 1200 * time.Millisecond}
+	board := gm.logic.Board()
+	dx, dy, dz := 0.0, 0.0, 0.0 // deck location.
+
+	// on start: find the deck and hide the shadows until the cards land.
+	a.intro = func() {
+		dx, dy, dz = dealOrigin(gm.save.DealOrigin, gm.scene.Cam(), gm.ww, gm.wh, gm.seedX, gm.seedY)
+		for cid := range board {
+			gm.hideShadow(uint(cid))
+		}
+	}
+
+	// during: each card flies in after the previous card has started.
+	// Cards hidden on the foundations of a won game are shown as they
+	// start their flight.
+	flying := [52]bool{}
+	a.during = func(t float64) {
+		for cid, bid := range board {
+			f := dealProgress(int(bid-8), len(board), t)
+			if f > 0 && !flying[cid] {
+				gm.cards[cid].Cull(false)
+				flying[cid] = true
+			}
+			x, y, z := placeCard(bid)
+			gm.cards[cid].SetAt(lerp(dx, x, f), lerp(dy, y, f), lerp(dz, z, f))
+			gm.cards[cid].SetAa(1, 0, 0, cardTilt(bid, gm.save.TiltCards)*f)
+			gm.drawn[cid].valid = false // redraw the card at the end.
+		}
+	}
+
+	// on end: place the cards exactly.
+	a.outro = func() {
+		gm.redrawBoard()
+	}
+	return a
+}
+
+// rayer projects screen pixels into the world, ie: vu.Camera.
+type rayer interface {
+	At() (x, y, z float64)
+	Ray(mx, my, ww, wh int) (x, y, z float64, err error)
+}
+
+// dealOrigin returns the world location of the deck for the given
+// DealOrigin preference. The seed button is at pixel location sx,sy,
+// and the deck is where the camera ray through the button reaches the
+// deck height. The deck is above the board if the ray misses.
+func dealOrigin(origin string, cam rayer, ww, wh, sx, sy int) (x, y, z float64) {
+	x, y, z = 0.0, 2.0, cardZ+0.5 // above the board.
+	if origin != dealOriginSeed {
+		return x, y, z
+	}
+	rx, ry, rz, err := cam.Ray(sx, sy, ww, wh)
+	cx, cy, cz := cam.At()
+	if err != nil || rz >= 0 || cz <= z {
+		return x, y, z // the ray can't reach the deck height.
+	}
+	t := (z - cz) / rz
+	return cx + rx*t, cy + ry*t, z
+}
+
+// dealProgress returns how far, 0 to 1, the given card has flown from
+// the deck at the deal animation ratio t. Cards are dealt in order with
+// the flights overlapping so that the first card starts at t=0 and the
+// last card lands at t=1.
+func dealProgress(order, cards int, t float64) float64 {
+	const flight = 0.25 // fraction of the deal spent moving each card.
+	start := 0.0
+	if cards > 1 {
+		start = (1 - flight) * float64(order) / float64(cards-1)
+	}
+	return min(1, max(0, (t-start)/flight))
+}
+
+// autoMoveDuration returns the animation time for the next auto move
+// given the previous move animation time and the player preferred
+// auto move delay in milliseconds. A negative delay speeds up
+// sequential moves. A zero delay means no animation.
+func autoMoveDuration(previous time.Duration, delay int) time.Duration {
+	if delay >= 0 {
+		return time.Duration(delay) * time.Millisecond
+	}
+
+	// speed up sequential moves.
+	maxspeed := 90 * time.Millisecond
+	slowdown := time.Duration(float64(previous) * 0.80)
+	return max(maxspeed, slowdown)
+}
+
+// collectDuration is the time to collect all the cards after a win.
+const collectDuration = 1600 * time.Millisecond
+
+// animateCollect scatters the cards of a won game across the board and
+// then sweeps them into their foundations, aces first. The cards are
+// already on the foundations, so this is only for show. Returns next
+// when the collect effect is off, otherwise next follows the collect.
+func animateCollect(gm *game, next Animation) Animation {
+	if gm.save.ReduceMotion || !gm.save.CollectWin {
+		return next
+	}
+	a := &animation{elapsed: 0, duration: collectDuration, next: next}
+	var scatter [52][2]float64 // x, y where each card starts.
+
+	// on start: scatter the cards, the same way for each game.
+	rng := rand.New(rand.NewSource(int64(gm.save.Seed)))
+	a.intro = func() {
+		for cid := range scatter {
+			scatter[cid] = [2]float64{lerp(-2.8, 2.8, rng.Float64()), lerp(-4.5, -0.5, rng.Float64())}
+			gm.hideShadow(uint(cid))
+		}
+	}
+
+	// during: cards fly to the foundations in rank order,
+	// each landing on top of the previous rank.
+	a.during = func(t float64) {
+		for cid := range scatter {
+			f := collectProgress(uint(cid), t)
+			x, y, z := placeCard(deck[cid].Suit + FC)
+			z += 0.002 * float64(deck[cid].Rank)
+			lift := 0.3 * math.Sin(f*math.Pi)
+			state := cardState{r: 1, g: 1, b: 1, a: 1, valid: true}
+			state.x, state.y = lerp(scatter[cid][0], x, f), lerp(scatter[cid][1], y, f)
+			state.z = lerp(cardZ+0.1, z, f) + lift
+			drawCard(gm.cards[cid], &gm.drawn[cid], state, false)
 		}
 	}
+
+	// on end: show the won board.
+	a.outro = func() {
+		gm.redrawBoard()
+	}
 	return a
 }
 
+// collectProgress returns how far, 0 to 1, the given card has flown to
+// its foundation at the collect animation ratio t. The deck is in rank
+// order, so the aces go first and the kings last.
+func collectProgress(cid uint, t float64) float64 {
+	return dealProgress(int(cid), len(deck), t)
+}
+
 // a very subdued "tada!" animation when the game is won.
 func animateGameComplete(gm *game) Animation {
 	a := &animation{elapsed: 0, duration: 2800 * time.Millisecond}
-	r, g, b := gameColor(gm.save.Seed)
+	r, g, b := boardColor(gm.save.Seed, gm.save.HighContrast)
 
 	// fade between regular background and end game background.
 	a.during = func(t float64) {
@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// go test -run AutoMove
+func TestAutoMoveDuration(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		previous time.Duration
+		delay    int
+		want     time.Duration
+	}{
+		{200 * ms, -1, 160 * ms}, // default speeds up sequential moves...
+		{100 * ms, -1, 90 * ms},  // ... until the max speed.
+		{200 * ms, 0, 0},         // instant.
+		{200 * ms, 500, 500 * ms},
+		{90 * ms, 300, 300 * ms}, // a fixed delay does not speed up.
+	}
+	for _, tt := range tests {
+		if got := autoMoveDuration(tt.previous, tt.delay); got != tt.want {
+			t.Errorf("previous %s delay %d: expected %s got %s", tt.previous, tt.delay, tt.want, got)
+		}
+	}
+}
+
+// go test -run MoveDuration
+func TestMoveDuration(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		distance float64
+		minimum  time.Duration
+		want     time.Duration
+	}{
+		{0, 100 * ms, 100 * ms},   // the shortest moves use the minimum...
+		{1.5, 100 * ms, 150 * ms}, // ... and scale with the distance...
+		{fullMoveDistance, 100 * ms, maxMoveDuration},
+		{10, 100 * ms, maxMoveDuration}, // ... up to the maximum.
+		{1.5, 0, 100 * ms},
+		{0, 500 * ms, maxMoveDuration}, // a large minimum is the maximum.
+		{0, -ms, 0},
+	}
+	for _, tt := range tests {
+		if got := moveDuration(tt.distance, tt.minimum); got != tt.want {
+			t.Errorf("distance %.1f minimum %s: expected %s got %s", tt.distance, tt.minimum, tt.want, got)
+		}
+	}
+
+	// moving a card to the next cascade is quicker than across the board.
+	next := moveDistance(map[uint]move{AC: {from: 8, to: 9}})
+	across := moveDistance(map[uint]move{AC: {from: 8, to: 15}, AD: {from: 9, to: 10}})
+	if next <= 0 || across <= next {
+		t.Fatalf("expected a longer move across the board got %f %f", next, across)
+	}
+	if moveDuration(next, 100*ms) >= moveDuration(across, 100*ms) {
+		t.Errorf("expected a short move to be quicker")
+	}
+	if moveDistance(map[uint]move{}) != 0 {
+		t.Errorf("expected no distance without moves")
+	}
+}
+
+// go test -run MovedCards
+func TestMovedCards(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	prev := l.Board()
+	l.Interact(H6)
+	l.Interact(EMPTY_PILE1) // 6H to a freecell.
+	moves := boardMoves(prev, l.Board())
+	if len(moves) != 1 || moves[H6].from != prev[H6] || moves[H6].to != 0 {
+		t.Fatalf("unexpected moves %v", moves)
+	}
+	if cards := movedCards(moves, true); len(cards) != 1 || cards[0] != H6 {
+		t.Errorf("expected 6H got %v", cards)
+	}
+	if cards := movedCards(moves, false); len(cards) != 0 {
+		t.Errorf("expected no cards when disabled got %v", cards)
+	}
+
+	// unmoved and buried foundation cards are not highlighted.
+	board := prev
+	board[C9], board[AC] = 1, FC+HIDDEN_CARD
+	if cards := movedCards(boardMoves(prev, board), true); len(cards) != 1 || cards[0] != C9 {
+		t.Errorf("expected 9C got %v", cards)
+	}
+}
+
+// go test -run DealProgress
+func TestDealProgress(t *testing.T) {
+	for _, ratio := range []float64{0, 0.3, 0.5, 0.9, 1} {
+		previous := 1.0
+		for order := 0; order < 52; order++ {
+			f := dealProgress(order, 52, ratio)
+			if f < 0 || f > 1 || f > previous {
+				t.Fatalf("ratio %.1f card %d: expected staggered progress got %f after %f", ratio, order, f, previous)
+			}
+			previous = f
+		}
+	}
+	if dealProgress(0, 52, 0) != 0 || dealProgress(0, 52, 0.25) != 1 {
+		t.Errorf("expected the first card to start immediately")
+	}
+	if dealProgress(51, 52, 0.75) != 0 || dealProgress(51, 52, 1) != 1 {
+		t.Errorf("expected the last card to land at the end")
+	}
+	if dealProgress(26, 52, 0.5) == 0 || dealProgress(26, 52, 0.5) == 1 {
+		t.Errorf("expected the middle card to be flying halfway through the deal")
+	}
+}
+
+// go test -run TrailSegments
+func TestTrailSegments(t *testing.T) {
+	m := move{from: 8, to: 15} // across the first cascade row.
+	if trail := trailSegments(m, 0.5, 0); len(trail) != 0 {
+		t.Errorf("expected no trail got %d segments", len(trail))
+	}
+
+	// at the start of the move the trail is bunched up under the card.
+	sx, sy, _ := placeCard(m.from)
+	for i, seg := range trailSegments(m, 0, 4) {
+		if seg.x != sx || seg.y != sy {
+			t.Errorf("segment %d: expected start %f,%f got %f,%f", i, sx, sy, seg.x, seg.y)
+		}
+	}
+
+	// later the segments follow the path back towards the start,
+	// each further behind and more faded than the last.
+	cx, _, _ := movePosition(m, 0.6)
+	trail := trailSegments(m, 0.6, 4)
+	if len(trail) != 4 {
+		t.Fatalf("expected 4 segments got %d", len(trail))
+	}
+	prev := trailSegment{x: cx, fade: 1}
+	for i, seg := range trail {
+		if seg.x >= prev.x || seg.x < sx || seg.fade >= prev.fade || seg.fade <= 0 {
+			t.Errorf("segment %d: expected behind %+v got %+v", i, prev, seg)
+		}
+		prev = seg
+	}
+}
+
+// go test -run UndoMoves
+func TestUndoMoves(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script[:6] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll() // includes foundation moves.
+	}
+
+	// each undo animates the cards back along the move it reverses.
+	for l.MoveNumber() > 0 {
+		forward := boardMoves(l.PreviousBoard(), l.Board())
+		back := boardMoves(l.Undo(), l.Board())
+		if len(forward) == 0 || len(back) != len(forward) {
+			t.Fatalf("expected %d undo moves got %v", len(forward), back)
+		}
+		for cid, m := range forward {
+			if back[cid] != (move{from: m.to, to: m.from}) {
+				t.Errorf("card %d expected %v reversed got %v", cid, m, back[cid])
+			}
+		}
+	}
+}
+
+// go test -run AnimStep
+func TestAnimStep(t *testing.T) {
+	fractions := []float64{}
+	finished := false
+	a := &animation{duration: 90 * time.Millisecond}
+	a.during = func(t float64) { fractions = append(fractions, t) }
+	a.outro = func() { finished = true }
+
+	// a frame hitch only advances the animation by one step.
+	var next Animation = a
+	next = next.Run(time.Second)
+	want := float64(maxAnimStep) / float64(a.duration)
+	if next == nil || finished || len(fractions) != 1 || fractions[0] != want {
+		t.Fatalf("expected fraction %f got %v", want, fractions)
+	}
+	for next != nil {
+		next = next.Run(time.Second)
+	}
+	if !finished || len(fractions) != 2 {
+		t.Errorf("expected the animation to finish after 3 steps got %v", fractions)
+	}
+}
+
+// go test -run Collect
+func TestCollect(t *testing.T) {
+	gm := &game{save: newSave(t.TempDir(), "freecell.save")}
+	done := animateGameComplete(gm)
+	a, ok := animateCollect(gm, done).(*animation)
+	if !ok || a.duration != collectDuration || a.next != done {
+		t.Fatalf("expected a collect animation followed by the celebration")
+	}
+	gm.save.ReduceMotion = true
+	if got := animateCollect(gm, done); got != done {
+		t.Errorf("expected no collect animation with reduced motion")
+	}
+
+	// lower ranks are always at least as far along as higher ranks.
+	for step := range 11 {
+		ratio := float64(step) / 10
+		for cid := AC; cid < KS; cid++ {
+			if deck[cid].Rank < deck[cid+1].Rank && collectProgress(cid, ratio) < collectProgress(cid+1, ratio) {
+				t.Fatalf("t %.1f: expected %s ahead of %s", ratio, deck[cid].Sym, deck[cid+1].Sym)
+			}
+		}
+	}
+	if collectProgress(AC, 0.1) == 0 || collectProgress(KS, 0.9) == 1 || collectProgress(KS, 1) != 1 {
+		t.Errorf("expected the aces first and the kings last")
+	}
+}
+
+// pinholeCam is a simple rayer for testing: looking down the -Z axis
+// from z=10 with a 90 degree field of view.
+type pinholeCam struct{}
+
+func (pinholeCam) At() (x, y, z float64) { return 0, 0, 10 }
+func (pinholeCam) Ray(mx, my, ww, wh int) (x, y, z float64, err error) {
+	if mx < 0 || mx > ww || my < 0 || my > wh {
+		return 0, 0, 0, fmt.Errorf("mouse not in window")
+	}
+	x, y, z = float64(2*mx)/float64(ww)-1, float64(2*my)/float64(wh)-1, -1
+	n := math.Sqrt(x*x + y*y + z*z)
+	return x / n, y / n, z / n, nil
+}
+
+// go test -run DealOrigin
+func TestDealOrigin(t *testing.T) {
+	near := func(x, y, z, wx, wy, wz float64) bool {
+		return math.Abs(x-wx) < 1e-9 && math.Abs(y-wy) < 1e-9 && math.Abs(z-wz) < 1e-9
+	}
+	cam, deckZ := pinholeCam{}, cardZ+0.5
+	if x, y, z := dealOrigin(dealOriginTop, cam, 800, 600, 400, 300); !near(x, y, z, 0, 2, deckZ) {
+		t.Errorf("expected the deck above the board got %f,%f,%f", x, y, z)
+	}
+
+	// the seed button maps to where its ray reaches the deck height.
+	if x, y, z := dealOrigin(dealOriginSeed, cam, 800, 600, 400, 300); !near(x, y, z, 0, 0, deckZ) {
+		t.Errorf("expected the center pixel straight ahead got %f,%f,%f", x, y, z)
+	}
+	dist := 10 - deckZ // camera to deck height.
+	if x, y, z := dealOrigin(dealOriginSeed, cam, 800, 600, 800, 600); !near(x, y, z, dist, dist, deckZ) {
+		t.Errorf("expected the top right corner at 45 degrees got %f,%f,%f", x, y, z)
+	}
+	if x, y, z := dealOrigin(dealOriginSeed, cam, 800, 600, 200, 450); !near(x, y, z, -dist/2, dist/2, deckZ) {
+		t.Errorf("expected the up left pixel at half the corner got %f,%f,%f", x, y, z)
+	}
+
+	// the deck is above the board if the button is not in the window.
+	if x, y, z := dealOrigin(dealOriginSeed, cam, 800, 600, 900, 300); !near(x, y, z, 0, 2, deckZ) {
+		t.Errorf("expected the deck above the board got %f,%f,%f", x, y, z)
+	}
+}
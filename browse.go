@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// browse.go lists the favorite and recently played games so that
+// a game can be picked using the arrow keys.
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+const (
+	maxRecent  = 10 // recently played games that are remembered.
+	browseRows = 8  // games shown at once in the browse list.
+)
+
+// seedList is the browse list of favorite games followed by the
+// recent games that are not favorites.
+type seedList struct {
+	seeds []uint // games in the order listed.
+	favs  int    // number of favorites at the start of the list.
+	focus int    // index of the highlighted game.
+}
+
+// newSeedList creates a browse list focused on the first game.
+func newSeedList(favorites, recent []uint) *seedList {
+	sl := &seedList{seeds: slices.Clone(favorites), favs: len(favorites)}
+	for _, seed := range recent {
+		if !slices.Contains(sl.seeds, seed) {
+			sl.seeds = append(sl.seeds, seed)
+		}
+	}
+	return sl
+}
+
+// move shifts the focus by the given number of games,
+// stopping at the ends of the list.
+func (sl *seedList) move(step int) {
+	sl.focus = max(0, min(sl.focus+step, len(sl.seeds)-1))
+}
+
+// selected returns the focused game.
+// Returns false if the list is empty.
+func (sl *seedList) selected() (seed uint, ok bool) {
+	if len(sl.seeds) == 0 {
+		return 0, false
+	}
+	return sl.seeds[sl.focus], true
+}
+
+// apply handles a browse list intent. The focus moves stop at the ends
+// of the list. Choosing the focused game also closes the list.
+func (sl *seedList) apply(it intent) (seed uint, chosen, closed bool) {
+	switch it {
+	case focusUpIntent:
+		sl.move(-1)
+	case focusDownIntent:
+		sl.move(1)
+	case chooseIntent:
+		seed, chosen = sl.selected()
+		return seed, chosen, true
+	case closeIntent:
+		return 0, false, true
+	}
+	return 0, false, false
+}
+
+// lines returns the text for up to rows games, scrolled so the
+// focused game is visible. The focused game is marked with ">"
+// and favorites with "*".
+func (sl *seedList) lines(rows int) []string {
+	if len(sl.seeds) == 0 {
+		return []string{"no favorites yet", "V marks a favorite"}
+	}
+	start := max(0, min(sl.focus-rows/2, len(sl.seeds)-rows))
+	lines := []string{}
+	for i := start; i < len(sl.seeds) && i < start+rows; i++ {
+		focus, fav := " ", " "
+		if i == sl.focus {
+			focus = ">"
+		}
+		if i < sl.favs {
+			fav = "*"
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %06d", focus, fav, sl.seeds[i]))
+	}
+	return lines
+}
+
+// openBrowser lists the favorite and recent games, pausing
+// the game until the list is closed.
+func (gm *game) openBrowser() {
+	if gm.anim != nil || gm.state != PlayState {
+		return
+	}
+	gm.browse = newSeedList(gm.save.Favorites, gm.save.Recent)
+	gm.state = BrowseState
+	gm.writeBrowser()
+}
+
+// runBrowse handles the key presses while the browse list is open.
+// Choosing a game changes to that game unless the seed is locked.
+// Leaving a game in progress keeps the list open until the choice
+// is confirmed.
+func (gm *game) runBrowse(pressed map[int32]bool) {
+	intents := listIntents(pressed, browseKeys)
+	if len(intents) == 0 {
+		return
+	}
+	for _, it := range intents {
+		seed, chosen, closed := gm.browse.apply(it)
+		if chosen && seed != gm.save.Seed && !gm.save.seedFixed() {
+			if !gm.confirmNewGame(time.Now()) {
+				continue // stay in the list for a second enter.
+			}
+			gm.save.persistSeed(seed)
+			gm.resetBoard()
+		}
+		if closed {
+			gm.closeBrowser()
+			return
+		}
+	}
+	gm.writeBrowser()
+}
+
+// writeBrowser shows the current browse list.
+func (gm *game) writeBrowser() {
+	if err := gm.browser.write(gm.eng, gm.browse.lines(browseRows)...); err != nil {
+		slog.Debug("browse text", "err", err)
+	}
+	gm.browser.show(true)
+}
+
+// closeBrowser hides the browse list and resumes play.
+func (gm *game) closeBrowser() {
+	gm.browser.show(false)
+	gm.browse = nil
+	gm.state = PlayState
+}
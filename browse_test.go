@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// go test -run BrowseSeeds
+func TestBrowseSeeds(t *testing.T) {
+	s := newSave(t.TempDir(), "freecell.save")
+	for seed := range uint(14) {
+		s.persistSeed(seed + 1)
+	}
+	s.persistSeed(5) // replaying moves the game to the front.
+	if len(s.Recent) != maxRecent || s.Recent[0] != 5 || s.Recent[1] != 14 || slices.Contains(s.Recent, 4) {
+		t.Fatalf("unexpected recent games %v", s.Recent)
+	}
+	if !s.toggleFavorite(42) || !s.toggleFavorite(14) || !s.toggleFavorite(7) || s.toggleFavorite(7) {
+		t.Fatalf("unexpected favorites %v", s.Favorites)
+	}
+
+	// favorites are listed first without repeating them as recent games.
+	sl := newSeedList(s.Favorites, s.Recent)
+	want := []uint{42, 14, 5, 13, 12, 11, 10, 9, 8, 7, 6}
+	if !slices.Equal(sl.seeds, want) {
+		t.Fatalf("expected %v got %v", want, sl.seeds)
+	}
+	if lines := sl.lines(browseRows); len(lines) != browseRows || lines[0] != ">* 000042" || lines[2] != "   000005" {
+		t.Errorf("unexpected list %q", lines)
+	}
+
+	// the focus moves, stopping at the ends of the list.
+	for _, it := range []intent{focusUpIntent, focusDownIntent, focusDownIntent, focusDownIntent} {
+		if _, chosen, closed := sl.apply(it); chosen || closed {
+			t.Fatalf("expected focus moves to keep browsing")
+		}
+	}
+	if seed, ok := sl.selected(); !ok || seed != 13 {
+		t.Errorf("expected focus on 13 got %d", seed)
+	}
+	for range 20 {
+		sl.apply(focusDownIntent)
+	}
+	if lines := sl.lines(browseRows); lines[len(lines)-1] != ">  000006" {
+		t.Errorf("expected the list to scroll to the focus got %q", lines)
+	}
+	if seed, chosen, closed := sl.apply(chooseIntent); seed != 6 || !chosen || !closed {
+		t.Errorf("expected to choose 6 got %d %t %t", seed, chosen, closed)
+	}
+	if _, chosen, closed := sl.apply(closeIntent); chosen || !closed {
+		t.Errorf("expected to close without choosing")
+	}
+
+	// an empty list can't choose a game.
+	empty := newSeedList(nil, nil)
+	empty.apply(focusDownIntent)
+	if _, chosen, closed := empty.apply(chooseIntent); chosen || !closed {
+		t.Errorf("expected nothing to choose")
+	}
+}
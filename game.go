@@ -9,11 +9,15 @@ import (
 	"bytes"
 	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	"image/png"
 	"log/slog"
 	"math"
 	"math/rand"
+	"os"
+	"path"
+	"slices"
 	"time"
 
 	"github.com/gazed/vu"
@@ -28,21 +32,90 @@ type game struct {
 	mx, my     int       // mouse positions
 	dx, dy     int       // mouse delta
 	ww, wh     int       // window dimensions
+	seedX      int       // seed button pixel location, the deal origin.
+	seedY      int       // for the seed-button DealOrigin preference.
 	save       *Save     // saved game data.
 	logic      *logic    // game rules.
 	state      int       // player action states.
 	gameOver   bool      // game has been won
+	wonAt      time.Time // when the game was won, zero until then.
+	hoverPick  uint      // card or empty pile under the mouse.
+	ptr        pointer   // tracks mouse and touch presses.
 	seedSelect []int32   // captures the game select key presses.
 	seedDial   int       // the game select speed dial progress.
 	seed01     float64   // 0:1 random value based on seed
 	gameStart  time.Time // used to track time since start.
 
+	// active play time excludes idle time.
+	lastInput  time.Time     // time of the last player input.
+	activeTime time.Duration // active play time up to the last input.
+
+	// bursts of prev and next presses only deal the final seed.
+	seeds seedDebounce
+
+	// true once the player has typed a digit, ie: a keyboard exists.
+	digitTyped bool
+
+	// true to highlight the aces and the cards blocking them.
+	showBlockers bool
+
+	// true if the current game can't affect the ranked win streak.
+	unranked bool
+
+	// true once the labelled card textures have been created.
+	labelledCards bool
+
+	// imported layout for the next deal, empty to deal the seed.
+	layout string
+
+	// number of cosmetic asset load failures that have been logged.
+	loggedFailures int
+
+	// resetting progress needs repeated key presses.
+	resetPresses int       // number of reset presses.
+	resetExpires time.Time // reset presses must be close together.
+
+	// manual foundation moves continue up the suit when SuitChain is set.
+	chaining  bool // true while a foundation move is being continued.
+	chainCard uint // the top card of the chain being continued.
+
+	// leaving a game with progress needs a second press.
+	leaveExpires time.Time // the second press must be before this.
+
+	// quitting needs a second key press when ConfirmQuit is set.
+	quitExpires time.Time // the second press must be before this.
+
 	// 3D game models.
-	scene *vu.Entity   // 3D root
-	light *vu.Entity   // scene light
-	cards []*vu.Entity // 3D deck cards
-	piles []*vu.Entity // 3D placeholders for empty card piles.
-	board *vu.Entity   // 3D background for the play surface.
+	scene   *vu.Entity            // 3D root
+	light   *vu.Entity            // scene light
+	cards   []*vu.Entity          // 3D deck cards
+	ghosts  []*vu.Entity          // optional card outlines at the start of a move.
+	ghosted [52]bool              // true for the ghosts that are shown.
+	shadows []*vu.Entity          // optional drop shadows behind the cards.
+	trails  map[uint][]*vu.Entity // optional trail quads of the current card moves.
+	piles   []*vu.Entity          // 3D placeholders for empty card piles.
+	done    []*vu.Entity          // marks the completed foundations.
+	board   *vu.Entity            // 3D background for the play surface.
+
+	// only cards that change are redrawn.
+	drawn       [52]cardState // last drawn state of each card.
+	shadowDrawn [52]cardState // last drawn state of each card shadow.
+	redraw      bool          // true to redraw all the cards.
+
+	// the player is told when the board has no moves left.
+	movesChecked [52]uint  // board last checked for moves.
+	stuckRedeal  time.Time // when a stuck repeated game is dealt again.
+
+	// guides new players. nil unless the tutorial is running.
+	tutor *tutorial
+
+	// idle players are invited to move by bobbing a suggested card.
+	invite   []uint      // cards being bobbed, nil if none.
+	inviteAt []cardState // where the bobbed cards are drawn.
+
+	// recently moved cards are briefly highlighted.
+	pulse      []uint    // cards being highlighted.
+	pulseStart time.Time // when the highlight started.
 
 	// 2D game UI.
 	ui         *vu.Entity // 2D root
@@ -54,13 +127,47 @@ type game struct {
 	scoreIcon  *vu.Entity // game score and previous highscore
 
 	// game UI text
-	text     *image.NRGBA // the text image update texture.
-	number   *vu.Entity   // text display for the game seed.
-	scores   *vu.Entity   // text display for the game score.
-	infoInit bool         // set true after the first update.
+	text        *image.NRGBA // the text image update texture.
+	number      *vu.Entity   // text display for the game seed.
+	scores      *vu.Entity   // text display for the game score.
+	infoInit    bool         // set true after the first update.
+	textDensity int          // text texture pixel density: 1, 2, or 3.
+
+	// brief messages shown to the player.
+	toast    *textLabel // text display for brief messages.
+	toastEnd time.Time  // when the current toast is hidden.
+
+	// won games are graded against the solver.
+	solved     chan solveResult // background solver result, nil if none.
+	gradeScore int              // winning move count being graded.
+	grade      string           // grade shown after the current toast.
+
+	// the last move is checked against the solver in the background.
+	undoChecked chan undoCheckResult // background undo check, nil if none.
+
+	// optional game information.
+	moveLabel *textLabel // current move number.
+	remaining *textLabel // cards not yet on the foundations.
+	statsHUD  *textLabel // game statistics summary.
+	moveLog   *textLabel // the last few moves.
+	par       *textLabel // par score for popular deals.
+
+	// shown while progress can't be saved.
+	saveWarning *textLabel
+
+	// favorite and recent games list.
+	browser *textLabel // the list, shown while browsing.
+	browse  *seedList  // the games being browsed, nil if not browsing.
+
+	// completed games list.
+	scoreboard *textLabel // the list, shown while open.
+	scoreList  *scoreList // the listed games, nil if not open.
 
 	// animation: moving a card, or end game celebration.
 	anim Animation // nil if no animation running.
+
+	// current engine frame rate cap.
+	frameLimit int
 }
 
 const (
@@ -68,6 +175,8 @@ const (
 	PlayState   = 0 // playing the current game seed.
 	SelectState = 1 // selecting a new game seed using digits.
 	DialState   = 2 // selecting a new game seed using hold and press.
+	BrowseState = 3 // picking a favorite or recent game from a list.
+	ScoreState  = 4 // browsing the completed games.
 
 	// size of the cards.
 	cardScale      = 0.06 // chosen by what looks good.
@@ -76,26 +185,85 @@ const (
 	halfCardWidth  = cardWidth * 0.5
 	halfCardHeight = cardHeight * 0.5
 	cardZ          = 0.0
+	cardTiltAngle  = 8.0 // degrees: optional cascade card tilt towards the player.
 
-	// size of UI text
+	// size of UI text at a pixel density of 1.
+	// Text textures and fonts are scaled up for high density displays.
 	txtWidth, txtHeight = 192.0, 192.0
+	txtFontSize         = 48 // font point size at a pixel density of 1.
+	maxTextDensity      = 3  // ie: 3x retina displays.
+
+	// below the minimum window size the UI is cramped and hard to read.
+	minWindowWidth, minWindowHeight = 400, 600
+	minButtonSize                   = 48.0 // pixels
+
+	// player button scale range, ie: larger buttons for accessibility.
+	minButtonScale, maxButtonScale = 0.75, 2.0
 
 	// button press hold delay is the time needed to consider
 	// a long press as a deliberate hold.
 	holdDelay = 0.75 // seconds.
+
+	// brief player messages.
+	toastDuration = 1500 * time.Millisecond
+
+	// the active game clock pauses after this much idle time.
+	idleTime = 30 * time.Second
+
+	// the frame rate is lowered to save power after this much idle time.
+	// The frame limits are the slowest and fastest allowed by the engine.
+	idleFrameDelay = 5 * time.Second
+	idleFrameLimit = 30
+	maxFrameLimit  = 240
+
+	// idle players are invited to move after this much idle time.
+	// The suggested card bobs up by inviteBob meters each invitePeriod.
+	idleInviteDelay = 20 * time.Second
+	invitePeriod    = 1200 * time.Millisecond
+	inviteBob       = 0.04
+
+	// moved cards highlight fade time.
+	pulseDuration = 400 * time.Millisecond
+
+	// prev and next seed changes are dealt after this quiet time.
+	seedQuietTime = 250 * time.Millisecond
+
+	// new games dealt faster than this snap into place.
+	dealRepeatTime = 400 * time.Millisecond
 )
 
+// debugKey handles developer only key presses.
+// debugKey is nil unless overridden by debug builds.
+var debugKey func(gm *game, press int32)
+
+// debugRecord is set by debug builds to record the player actions that
+// change the board, see replayStep.
+var debugRecord func(kind string, value uint, text string)
+
+// record passes a player action to the debug recorder, if any.
+func (gm *game) record(kind string, value uint) { gm.recordText(kind, value, "") }
+
+// recordText passes a player action that needs more than a value to the
+// debug recorder, if any. The text is a deal variant, layout, or history.
+func (gm *game) recordText(kind string, value uint, text string) {
+	if debugRecord != nil {
+		debugRecord(kind, value, text)
+	}
+}
+
 // createGame is called once on startup.
 // Use seed 25904 (easy game) for testing.
 func createGame(eng *vu.Engine, ww, wh int, save *Save) *game {
 	gm := &game{eng: eng, ww: ww, wh: wh, save: save}
-	gm.logic = &logic{}
+	gm.logic = &logic{undoKeepsSelection: save.UndoKeepsSelection}
+	gm.logic.singleCardMoves, gm.logic.closedCells = variantRules(save.variant())
+	pileSlots = pileLayoutSlots(save.PileLayout)
 
 	// load 2D assets
 	eng.ImportAssets("icon.shd", "tint.shd")                          // shaders
 	eng.ImportAssets("crown.png", "next.png", "prev.png", "undo.png") // buttons
 	eng.ImportAssets("seed.png", "unsolvable.png")                    // more buttons
-	eng.ImportAssets("48:hack.ttf")                                   // fonts
+	eng.ImportAssets("48:hack.ttf", "96:hack.ttf", "144:hack.ttf")    // fonts
 
 	// create the 2D UI
 	gm.ui = eng.AddScene(vu.Scene2D)
@@ -111,13 +279,7 @@ func createGame(eng *vu.Engine, ww, wh int, save *Save) *game {
 	gm.unsolvable = gm.ui.AddModel("shd:icon", "msh:icon", "tex:color:unsolvable").SetLayer(3)
 
 	// create the UI text using double buffered text.
-	gm.text = image.NewNRGBA(image.Rect(0, 0, txtWidth, txtHeight))
-	gm.scores = gm.ui.AddModel("shd:tint", "msh:icon", "fnt:hack24")
-	gm.scores.SetColor(0, 0, 0, 1).SetLayer(2)
-	gm.scores.AddUpdatableTexture(gm.eng, "scores", gm.text)
-	gm.number = gm.ui.AddModel("shd:tint", "msh:icon", "fnt:hack48")
-	gm.number.AddUpdatableTexture(gm.eng, "number", gm.text)
-	gm.number.SetColor(0, 0, 0, 1).SetLayer(2)
+	gm.createText(1)
 
 	// load the 3D assets
 	eng.ImportAssets("card.shd", "tex3D.shd", "board.shd")   // shaders
@@ -127,7 +289,11 @@ func createGame(eng *vu.Engine, ww, wh int, save *Save) *game {
 
 	// creates card assets: card0 to card51, an empty pile,
 	// and the foundation empty piles.
-	gm.createCardAssets()
+	gm.createCardAssets(plainCardTextures, false)
+	if gm.save.CardLabels {
+		gm.createCardAssets(labelledCardTextures, true)
+		gm.labelledCards = true
+	}
 
 	// create the 3D scene
 	gm.scene = eng.AddScene(vu.Scene3D)
@@ -147,10 +313,12 @@ func createGame(eng *vu.Engine, ww, wh int, save *Save) *game {
 	gm.piles = make([]*vu.Entity, 16)
 	for pid := range gm.piles {
 		tex := pileTextures[pid]
-		emptyPile := gm.scene.AddModel("shd:tex3D", "msh:card", "tex:color:"+tex)
-		emptyPile.SetScale(cardScale, cardScale, 0.0)
+		emptyPile := gm.scene.AddModel("shd:card", "msh:card", "tex:color:"+tex)
+		emptyPile.SetScale(cardScale, cardScale, 0.0).SetColor(1, 1, 1, 1)
 		if pid >= int(FC) && pid <= int(FS) {
 			emptyPile.SetScale(cardScale*1.05, cardScale*1.05, 0.0)
+			r, g, b := foundationTint(uint(pid))
+			emptyPile.SetColor(r, g, b, 1) // hint at the foundation suit.
 		}
 		gm.piles[pid] = emptyPile
 	}
@@ -158,23 +326,54 @@ func createGame(eng *vu.Engine, ww, wh int, save *Save) *game {
 	// create the cards.
 	gm.cards = make([]*vu.Entity, KS+1)
 	for cid := AC; cid <= KS; cid++ {
-		tex := fmt.Sprintf("card%d", cid)
-		card := gm.scene.AddModel("shd:card", "msh:card", "tex:color:"+tex)
-		card.SetScale(cardScale, cardScale, cardScale).SetColor(1, 1, 1, 1)
-		gm.cards[cid] = card
+		gm.cards[cid] = gm.addCard(cid)
+	}
+
+	// mark completed foundations with a crown over the king.
+	gm.done = make([]*vu.Entity, 4)
+	for suit := CLB; suit <= SPD; suit++ {
+		mark := gm.scene.AddModel("shd:tex3D", "msh:quad", "tex:color:crown")
+		mark.SetScale(0.3, 0.3, 0).Cull(true)
+		gm.done[suit] = mark
+	}
+
+	// create the hidden move ghosts using the empty pile texture.
+	gm.ghosts = make([]*vu.Entity, KS+1)
+	for cid := AC; cid <= KS; cid++ {
+		ghost := gm.scene.AddModel("shd:tex3D", "msh:card", "tex:color:card52")
+		ghost.SetScale(cardScale, cardScale, 0.0).Cull(true)
+		gm.ghosts[cid] = ghost
+	}
+
+	// create the hidden card shadows. The card shader tints the empty
+	// pile texture with the dark translucent shadow color.
+	gm.shadows = make([]*vu.Entity, KS+1)
+	for cid := AC; cid <= KS; cid++ {
+		shadow := gm.scene.AddModel(shadowShader, "msh:card", "tex:color:card52")
+		shadow.SetScale(cardScale, cardScale, 0.0).Cull(true)
+		gm.shadows[cid] = shadow
 	}
 
 	// fresh deal based on the current seed.
 	gm.resetBoard()
+	if save.firstRun() {
+		gm.startTutorial()
+	}
 	return gm
 }
 
 // Resize updates the window dimensions needed for ray picking.
 func (gm *game) Resize(wx, wy, ww, wh int) {
 	gm.ww, gm.wh = ww, wh
+	gm.redraw = true
 
 	// only need to save changes to the non-fullscreen location and size.
-	if !(wx == 0 && wy == 0) &&
+	// Windows below the minimum size are not saved.
+	tooSmall := ww < minWindowWidth || wh < minWindowHeight
+	if tooSmall {
+		slog.Warn("window below minimum size", "w", ww, "h", wh)
+	}
+	if !(wx == 0 && wy == 0) && !tooSmall &&
 		!(wx == gm.save.Display.Wx && wy == gm.save.Display.Wy &&
 			ww == gm.save.Display.Ww && wh == gm.save.Display.Wh) {
 		gm.save.persistWindow(wx, wy, ww, wh)
@@ -192,15 +391,19 @@ func (gm *game) Resize(wx, wy, ww, wh int) {
 	xmax, ymax := cx+fw*0.5, cy+fh*0.5 // bottom right pixel location.
 
 	// buttons are a fraction of available width
-	buttonSize := min(fw*0.4, 160.0)
-	pixelGap := 40.0
-	gm.undoButton.SetScale(buttonSize, buttonSize, 0).SetAt(xmin+0.5*buttonSize+pixelGap, ymax-buttonSize, 0)
-	gm.prevButton.SetScale(buttonSize*0.5, buttonSize, 0).SetAt(xmax-2.75*buttonSize-pixelGap, ymax-buttonSize, 0)
-	gm.nextButton.SetScale(buttonSize*0.5, buttonSize, 0).SetAt(xmax-0.25*buttonSize-pixelGap, ymax-buttonSize, 0)
-	gm.seedButton.SetScale(buttonSize*2.0, buttonSize, 0).SetAt(xmax-1.5*buttonSize-pixelGap, ymax-buttonSize, 0)
+	buttonSize, pixelGap := buttonLayout(fw, gm.save.ButtonScale)
+	undoX, prevX, nextX, seedX := buttonXs(xmin, xmax, buttonSize, pixelGap, gm.save.LeftHanded)
+	gm.undoButton.SetScale(buttonSize, buttonSize, 0).SetAt(undoX, ymax-buttonSize, 0)
+	gm.prevButton.SetScale(buttonSize*0.5, buttonSize, 0).SetAt(prevX, ymax-buttonSize, 0)
+	gm.nextButton.SetScale(buttonSize*0.5, buttonSize, 0).SetAt(nextX, ymax-buttonSize, 0)
+	gm.seedButton.SetScale(buttonSize*2.0, buttonSize, 0).SetAt(seedX, ymax-buttonSize, 0)
+	gm.seedX, gm.seedY = int(seedX), int(ymax-buttonSize)
 
 	// place the score icon and text.
 	textSize := buttonSize * 1.2
+	if density := textDensity(textSize * 1.5); density != gm.textDensity {
+		gm.createText(density) // sharper text for high density displays.
+	}
 	sx, sy := cx, ymax-buttonSize*1.2
 	gm.scoreIcon.SetScale(buttonSize*1.4, buttonSize*1.4, 0).SetAt(sx-buttonSize, sy, 0)
 	gm.unsolvable.SetScale(buttonSize*1.4, buttonSize*1.4, 0).SetAt(sx-buttonSize, sy, 0)
@@ -216,21 +419,251 @@ func (gm *game) Resize(wx, wy, ww, wh int) {
 	sy += buttonSize * 0.65
 	gm.number.SetAt(sx, sy, 0).SetScale(textSize, textSize, 0)
 
+	// place the toast text centered above the buttons.
+	lineHeight := textSize / 3
+	gm.toast.place(cx, ymax-buttonSize*2.2, lineHeight)
+
+	// place the par score to the right of the scores.
+	gm.par.place(cx+buttonSize*1.6, ymax-buttonSize*1.2, lineHeight)
+
+	// place the move number in the top left corner.
+	gm.moveLabel.place(xmin+pixelGap+lineHeight*2.5, pixelGap+lineHeight*0.5, lineHeight)
+	gm.remaining.place(xmin+pixelGap+lineHeight*2.5, pixelGap+lineHeight*2.5, lineHeight)
+	gm.statsHUD.place(xmin+pixelGap+lineHeight*6.5, pixelGap+lineHeight*1.5, lineHeight)
+
+	// place the move log in the top right corner.
+	gm.moveLog.place(xmax-pixelGap-lineHeight*2.5, pixelGap+lineHeight*moveLogRows*0.5, lineHeight)
+
+	// place the browse list in the middle of the board.
+	gm.browser.place(cx, cy, lineHeight)
+	gm.scoreboard.place(cx, cy, lineHeight)
+
+	// place the save warning across the top, below the optional labels.
+	gm.saveWarning.place(cx, pixelGap+lineHeight*3.5, lineHeight)
+
 	// reset the card piles
-	for pid := range uint(16) {
-		x, y, z := placePile(pid)
-		gm.piles[pid].SetAt(x, y, z)
-	}
+	gm.placePiles()
 
 	// handle different aspect ratios by adjusting the camera position.
 	// Needed to handle fixed screen sizes like ipad 3:4 and iphone 9:16.
 	// Note: heuristic works ok for most reasonable screen ratios.
 	// The board height is ignored for the distance calculation.
+	// The 10.5 width was tuned with the 90 degree fov used as radians,
+	// which shows about 6.5 of the 5.9 wide board at any ratio.
 	camHeight := -2.5 * fh / fw
 	camDistance := gm.camToBoardDistance(10.5, 0.0, 90.0, fw/fh)
+	if gm.save.FixedCardSize {
+		// keep the card size and only center the camera on the board.
+		// Small windows clip the board and large windows show more table.
+		camHeight, camDistance = -2.5, fixedCamDistance(fh)
+	}
 	gm.scene.Cam().SetAt(0.0, camHeight, camDistance)
 }
 
+// fixedCardHeight is the card height in pixels when the card size is fixed.
+const fixedCardHeight = 160.0
+
+// fixedCamDistance returns the camera distance that shows the cards at
+// fixedCardHeight pixels for the given window height. The camera has a
+// 90 degree vertical field of view, so the visible board height is twice
+// the camera distance.
+func fixedCamDistance(wh float64) float64 {
+	return wh * cardHeight * cardScale / (2 * fixedCardHeight)
+}
+
+// buttonXs returns the button center x pixel locations for a window
+// spanning xmin to xmax. Undo is on the left and the game buttons are on
+// the right, unless leftHanded mirrors them.
+func buttonXs(xmin, xmax, buttonSize, pixelGap float64, leftHanded bool) (undo, prev, next, seed float64) {
+	undo = xmin + 0.5*buttonSize + pixelGap
+	prev = xmax - 2.75*buttonSize - pixelGap
+	next = xmax - 0.25*buttonSize - pixelGap
+	seed = xmax - 1.5*buttonSize - pixelGap
+	if leftHanded {
+		mirror := func(x float64) float64 { return xmin + xmax - x }
+		undo, prev, next, seed = mirror(undo), mirror(prev), mirror(next), mirror(seed)
+	}
+	return undo, prev, next, seed
+}
+
+// buttonLayout returns the button size and edge gap in pixels for the
+// given window width and player button scale. Buttons are a fraction of
+// the available width, but are kept from overlapping and from getting
+// too small to press.
+func buttonLayout(fw, scale float64) (buttonSize, pixelGap float64) {
+	scale = buttonScale(scale)
+	pixelGap = 40.0 * scale
+	buttonSize = min(fw*0.4, 160.0*scale, (fw-2*pixelGap)*0.25) // 4 buttons fit across.
+	return max(buttonSize, minButtonSize), pixelGap
+}
+
+// buttonScale returns the player button scale clamped to the supported
+// range. Saves without a button scale use full size buttons.
+func buttonScale(scale float64) float64 {
+	if scale == 0 {
+		return 1.0
+	}
+	return max(minButtonScale, min(scale, maxButtonScale))
+}
+
+// createText creates the UI text models and their updatable text texture.
+// The text texture and font size are scaled by the pixel density.
+// Any previous text models are replaced.
+func (gm *game) createText(density int) {
+	if gm.scores != nil {
+		gm.scores.Dispose(gm.eng)
+	}
+	if gm.number != nil {
+		gm.number.Dispose(gm.eng)
+	}
+	if gm.toast != nil {
+		gm.toast.dispose(gm.eng)
+	}
+	if gm.moveLabel != nil {
+		gm.moveLabel.dispose(gm.eng)
+	}
+	if gm.remaining != nil {
+		gm.remaining.dispose(gm.eng)
+	}
+	if gm.statsHUD != nil {
+		gm.statsHUD.dispose(gm.eng)
+	}
+	if gm.moveLog != nil {
+		gm.moveLog.dispose(gm.eng)
+	}
+	if gm.browser != nil {
+		gm.browser.dispose(gm.eng)
+	}
+	if gm.saveWarning != nil {
+		gm.saveWarning.dispose(gm.eng)
+	}
+	if gm.par != nil {
+		gm.par.dispose(gm.eng)
+	}
+	if gm.scoreboard != nil {
+		gm.scoreboard.dispose(gm.eng)
+	}
+	gm.textDensity = density
+	w, h := textTextureSize(density)
+	gm.text = image.NewNRGBA(image.Rect(0, 0, w, h))
+	gm.scores = gm.ui.AddModel("shd:tint", "msh:icon", "fnt:"+textFont(density))
+	gm.scores.SetColor(0, 0, 0, 1).SetLayer(2)
+	gm.scores.AddUpdatableTexture(gm.eng, fmt.Sprintf("scores%d", density), gm.text)
+	gm.number = gm.ui.AddModel("shd:tint", "msh:icon", "fnt:"+textFont(density))
+	gm.number.AddUpdatableTexture(gm.eng, fmt.Sprintf("number%d", density), gm.text)
+	gm.number.SetColor(0, 0, 0, 1).SetLayer(2)
+
+	// single line text labels.
+	gm.toast = newTextLabel(gm.eng, gm.ui, "toast", 20, 1, density)
+	gm.toast.show(false)
+	gm.moveLabel = newTextLabel(gm.eng, gm.ui, "move", 10, 1, density)
+	gm.moveLabel.show(false)
+	gm.remaining = newTextLabel(gm.eng, gm.ui, "remaining", 10, 1, density)
+	gm.remaining.show(false)
+	gm.statsHUD = newTextLabel(gm.eng, gm.ui, "stats", 24, 1, density)
+	gm.statsHUD.show(false)
+	gm.saveWarning = newTextLabel(gm.eng, gm.ui, "saveWarning", len(saveWarningText), 1, density)
+	gm.saveWarning.show(false)
+	gm.par = newTextLabel(gm.eng, gm.ui, "par", 7, 1, density)
+	gm.par.show(false)
+
+	// multi line text labels.
+	gm.moveLog = newTextLabel(gm.eng, gm.ui, "moveLog", 10, moveLogRows, density)
+	gm.moveLog.show(false)
+	gm.browser = newTextLabel(gm.eng, gm.ui, "browse", 18, browseRows, density)
+	gm.browser.show(false)
+	if gm.browse != nil {
+		gm.writeBrowser()
+	}
+	gm.scoreboard = newTextLabel(gm.eng, gm.ui, "scoreboard", 30, scoreboardRows+1, density)
+	gm.scoreboard.show(false)
+	if gm.scoreList != nil {
+		gm.writeScoreboard()
+	}
+	gm.infoInit = false // rewrite the text once the font is available.
+}
+
+// textDensity returns the text pixel density needed to display
+// text of the given pixel size without upscaling the text texture.
+// The engine does not report the display pixel density, so it is
+// derived from the on screen text size.
+func textDensity(textSize float64) int {
+	density := int(math.Ceil(textSize / txtWidth))
+	return max(1, min(density, maxTextDensity))
+}
+
+// textTextureSize returns the text texture pixel dimensions
+// for the given pixel density.
+func textTextureSize(density int) (w, h int) {
+	return int(txtWidth) * density, int(txtHeight) * density
+}
+
+// textFont returns the font asset name for the given pixel density.
+func textFont(density int) string {
+	return fmt.Sprintf("hack%d", txtFontSize*density)
+}
+
+// placePiles positions the empty piles and the completed foundation crowns.
+func (gm *game) placePiles() {
+	for pid := range uint(16) {
+		x, y, z := placePile(pid)
+		gm.piles[pid].SetAt(x, y, z)
+	}
+	for suit := CLB; suit <= SPD; suit++ {
+		x, y, _ := placeCard(suit + FC)
+		gm.done[suit].SetAt(x, y+0.35, cardZ+0.05)
+	}
+}
+
+// nextPileLayout switches to the next free cell and foundation layout.
+func (gm *game) nextPileLayout() {
+	if gm.anim != nil || gm.state != PlayState {
+		return
+	}
+	next := pileLayouts[(slices.Index(pileLayouts, gm.save.PileLayout)+1)%len(pileLayouts)]
+	gm.save.PileLayout = next
+	gm.save.persist()
+	pileSlots = pileLayoutSlots(next)
+	gm.placePiles()
+	gm.redraw = true
+	gm.redrawBoard()
+	gm.showToast(next)
+}
+
+// nextHighlight switches to the next selection color preset.
+// A custom color switches to the first preset.
+func (gm *game) nextHighlight() {
+	if gm.anim != nil || gm.state != PlayState {
+		return
+	}
+	next := highlightColors[(slices.Index(highlightColors, gm.save.HighlightColor)+1)%len(highlightColors)]
+	gm.save.HighlightColor = next
+	gm.save.persist()
+	gm.redrawBoard()
+	gm.showToast(next + " selection")
+}
+
+// pileSlots maps the free cell and foundation board locations, 0 to 7,
+// to their top row columns. Only where the piles are drawn changes,
+// so picks and moves still use the board locations.
+var pileSlots = pileLayoutSlots(pileLayoutClassic)
+
+// pileLayoutSlots returns the top row column for each free cell
+// and foundation in the given layout.
+func pileLayoutSlots(layout string) (slots [8]uint) {
+	for bid := range uint(8) {
+		switch layout {
+		case pileLayoutFoundationsLeft:
+			slots[bid] = (bid + 4) % 8
+		case pileLayoutMirrored:
+			slots[bid] = 7 - bid
+		default:
+			slots[bid] = bid
+		}
+	}
+	return slots
+}
+
 // placePile positions the empty card piles.
 func placePile(boardID uint) (x, y, z float64) {
 	x, y, z = placeCard(boardID) // same x,y
@@ -254,6 +687,9 @@ func placeCard(boardID uint) (x, y, z float64) {
 		}
 	}
 	row, col := float64(boardID/8), float64(boardID%8)
+	if row == 0 {
+		col = float64(pileSlots[boardID]) // free cells and foundations.
+	}
 
 	// the cascade starts in the row 1, and the subsequent
 	// rows are overlapped.
@@ -298,44 +734,96 @@ func (gm *game) camVerticalDistanceToTargetTop(FOV, size float64) float64 {
 // engine tick where delta is the elapsed time since the last call.
 func (gm *game) Update(eng *vu.Engine, in *vu.Input, delta time.Duration) {
 
-	// check for serious problems.
+	// check for serious problems. The game continues without
+	// cosmetic assets, ie: a missing image.
 	if eng.LoadErrors() {
-		slog.Error("stopping due to asset loading errors")
-		eng.Shutdown()
-		return
+		failed := loadFailures()
+		if fatalLoadErrors(failed) {
+			slog.Error("stopping due to asset loading errors", "assets", failed)
+			eng.Shutdown()
+			return
+		}
+		if len(failed) > gm.loggedFailures {
+			slog.Warn("continuing without assets", "assets", failed[gm.loggedFailures:])
+			gm.loggedFailures = len(failed)
+		}
 	}
 
 	// update user mouse moves.
 	gm.dx, gm.dy = gm.mx-int(in.Mx), gm.my-int(in.My)
 	gm.mx, gm.my = int(in.Mx), int(in.My)
+	if len(in.Pressed) > 0 || gm.dx != 0 || gm.dy != 0 {
+		gm.trackInput(time.Now())
+	}
+	for key := range in.Pressed {
+		gm.digitTyped = gm.digitTyped || isDigit(key)
+	}
+	gm.updateInvite(time.Now()) // stops on input before any moves.
+
+	// deal the final seed after a burst of prev and next presses.
+	// A repeated game is dealt again.
+	if seed, ok := gm.seeds.commit(time.Now()); ok && (seed != gm.save.Seed || gm.save.RepeatUntilWon) {
+		if gm.confirmNewGame(time.Now()) {
+			gm.save.Seed = seed
+			gm.save.persistSeed(seed)
+			gm.resetBoard()
+		} else {
+			gm.updateGameSeed(fmt.Sprintf("%06d", gm.save.Seed)) // stay in the current game.
+		}
+	}
+
+	// Touches are a single point: vu leaves ios multi-touch disabled so
+	// only the primary touch is reported, as the mouse location, and
+	// secondary fingers are ignored. A new touch jumps the location from
+	// wherever the last touch ended, so it is not a drag. Ignoring the
+	// jump prevents the seed dial from lurching when a touch begins.
+	if _, ok := in.Pressed[vu.TOUCH]; ok {
+		gm.dx, gm.dy = 0, 0
+	}
+
+	// lower the frame rate while idle to save power. This also slows
+	// the background shader updates.
+	busy := gm.anim != nil || gm.state != PlayState
+	if limit := frameLimit(gm.save.FrameLimit, isIdle(time.Now(), gm.lastInput, busy)); limit != gm.frameLimit {
+		gm.frameLimit = limit
+		eng.SetFrameLimit(limit)
+	}
 
 	// update background shader
-	timer := time.Since(gm.gameStart)
-	ticker := timer.Seconds()
+	ticker := backgroundTicker(time.Now(), gm.gameStart, gm.wonAt)
 	gm.board.SetModelUniform("args4", []float32{float32(gm.ww), float32(gm.wh), float32(ticker), float32(gm.seed01)})
 
 	// highlight buttons if over.
 	gm.handleHover(gm.mx, gm.my)
 
+	// hide expired toasts.
+	if !gm.toastEnd.IsZero() && time.Now().After(gm.toastEnd) {
+		gm.toast.show(false)
+		gm.toastEnd = time.Time{}
+	}
+	gm.updatePulse(time.Now())
+	if gm.trackPlayTime(delta) && gm.save.ShowStats {
+		gm.updateInfo() // refresh the play time.
+	}
+
+	// the browse list takes the key presses while it is open.
+	if gm.state == BrowseState {
+		gm.runBrowse(in.Pressed)
+		return
+	}
+	if gm.state == ScoreState {
+		gm.runScoreboard(in.Pressed)
+		return
+	}
+
 	// handle one time key presses.
-	for press := range in.Pressed {
-		switch press {
-		case vu.KQ: // quit game
-			eng.Shutdown() // game is saved in main.
-		case vu.KF11, vu.KF:
-			// F11 is the standard window key for toggling fullscreen.
-			// F is also commonly used.
-			// macos Ctrl-Cmd-F is handled automatically by the macos window manager.
-			eng.ToggleFullscreen()
-			gm.save.Full = !gm.save.Full
-			gm.save.persistFullScreen(gm.save.Full)
-		case vu.KARight:
-			gm.nextGame()
-		case vu.KALeft:
-			gm.prevGame()
-		case vu.KT:
-			// play the end game effect.
-			gm.anim = animateGameComplete(gm)
+	intents, unbound := keyPresses(in.Pressed)
+	for _, it := range intents {
+		gm.dispatch(eng, it)
+	}
+	for _, press := range unbound {
+		if debugKey != nil {
+			debugKey(gm, press)
 		}
 	}
 
@@ -356,22 +844,10 @@ func (gm *game) Update(eng *vu.Engine, in *vu.Input, delta time.Duration) {
 		gm.runSpeedDial(eng, in, delta)
 	case PlayState:
 		// regular game play
-		for press := range in.Pressed {
-			switch {
-			case press == vu.KML || press == vu.TOUCH:
-				gm.handleButtonClick(gm.mx, gm.my)
-				gm.handleCardClick()
-			}
-		}
-
-		// react to continuous press events.
-		for press, startPress := range in.Down {
-			switch {
-			case press == vu.KML || press == vu.TOUCH:
-				timeDown := time.Now().Sub(startPress)
-				gm.handleButtonHold(gm.mx, gm.my, timeDown)
-			}
+		for _, it := range gm.ptr.intents(in, gm.save.TapTolerance, gm.save.SwipeThreshold) {
+			gm.dispatch(eng, it)
 		}
+		gm.handleCardHover()
 		if gm.state == SelectState {
 			gm.updateGameSeed("------")
 			return // start running SelectState next update
@@ -380,136 +856,1201 @@ func (gm *game) Update(eng *vu.Engine, in *vu.Input, delta time.Duration) {
 		slog.Debug("invalid game state", "state", gm.state)
 	}
 
-	// check if the game has finished.
-	if !gm.gameOver {
-		gm.gameOver = gm.logic.IsGameWon()
-		if gm.gameOver {
-			score := uint(gm.logic.MoveCount())
-			slog.Info("game complete", "seed", gm.save.Seed, "score", score)
+	// check if the game has finished.
+	if !gm.gameOver {
+		gm.gameOver = gm.logic.IsGameWon()
+		if gm.gameOver {
+			gm.wonAt = time.Now() // calm the background.
+			score := uint(gm.logic.MoveCount())
+			clock := gm.gameClock(time.Now())
+			slog.Info("game complete", "seed", gm.save.Seed, "score", score, "time", clock)
+			gm.showToast(clockText(clock))
+
+			// update the best score and the statistics.
+			// Custom games are not scored since they have no seed.
+			if !gm.logic.custom {
+				gm.save.recordAttemptsToWin(gm.save.Seed)
+				if bestScore, ok := gm.save.scores()[gm.save.Seed]; !ok || score < bestScore {
+					gm.save.scores()[gm.save.Seed] = score
+				}
+				gm.save.recordWin()
+				gm.save.recordTime(gm.save.Seed, clock)
+				gm.save.recordWinDate(gm.save.Seed, time.Now())
+				gm.gradeWin(gm.save.Seed, int(score))
+				gm.save.persist()
+			}
+			gm.updateInfo()
+			gm.clearTrails()
+			gm.anim = animateCollect(gm, animateGameComplete(gm))
+		}
+	}
+
+	// show the move grade once the solver has finished.
+	select {
+	case result := <-gm.solved:
+		gm.solved = nil
+		if result.ok {
+			gm.save.recordSolverMoves(result.variant, result.seed, result.length)
+			gm.save.persist()
+			if result.seed == gm.save.Seed && result.variant == gm.save.variant() && gm.gameOver {
+				gm.grade = efficiencyGrade(gm.gradeScore, result.length)
+			}
+		}
+	default:
+	}
+	if gm.grade != "" && gm.toastEnd.IsZero() {
+		gm.showToast(gm.grade)
+		gm.grade = ""
+	}
+
+	// show the undo check unless the player has moved on.
+	select {
+	case result := <-gm.undoChecked:
+		gm.undoChecked = nil
+		if result.board == gm.logic.Board() && !gm.gameOver {
+			gm.showToast(undoCheckText(result.helps, result.computed))
+		}
+	default:
+	}
+
+	// check once for each new board position if the player is stuck.
+	if !gm.gameOver && gm.anim == nil && gm.logic.Board() != gm.movesChecked {
+		gm.movesChecked = gm.logic.Board()
+		gm.stuckRedeal = time.Time{} // the player undid out of a stuck position.
+		if !gm.logic.MovesExist() {
+			gm.showToast("no moves left")
+			if gm.save.RepeatUntilWon {
+				gm.stuckRedeal = time.Now().Add(toastDuration)
+			}
+		}
+	}
+
+	// try a stuck repeated game again once the player has been told.
+	if stuckRedealDue(gm.stuckRedeal, time.Now(), gm.anim != nil) {
+		gm.resetBoard()
+	}
+
+	// wait for the font to load before the initial text update.
+	// Afterwards only need to update if it changes.
+	if !gm.infoInit {
+		gm.infoInit = gm.updateInfo()
+	}
+}
+
+// resetProgress clears the player scores once the reset key has been
+// pressed and then confirmed twice. Presses that are too far apart
+// start over.
+func (gm *game) resetProgress(now time.Time) {
+	if now.After(gm.resetExpires) {
+		gm.resetPresses = 0
+	}
+	gm.resetPresses++
+	gm.resetExpires = now.Add(2 * toastDuration)
+	msg, reset := resetPrompt(gm.resetPresses)
+	if reset {
+		gm.resetPresses = 0
+		gm.save.resetProgress()
+		gm.updateInfo()
+	}
+	gm.showToast(msg)
+}
+
+// trackPlayTime adds the elapsed time to the lifetime play time while
+// a game is being played, periodically saving the total.
+// Returns true if the total was saved.
+func (gm *game) trackPlayTime(delta time.Duration) bool {
+	if gm.state != PlayState || !gm.save.addPlayTime(delta) {
+		return false
+	}
+	gm.save.persist()
+	return true
+}
+
+// startTutorial deals the tutorial game and shows the first hint.
+func (gm *game) startTutorial() {
+	gm.save.persistSeed(tutorialSeed)
+	gm.resetBoard()
+	gm.tutor = newTutorial()
+	gm.redrawBoard()
+	gm.showToast(gm.tutor.hint())
+}
+
+// tutorialClick plays a tutorial pick, ignoring picks that are not the
+// next tutorial move. The tutorial ends after the last step.
+func (gm *game) tutorialClick(pick uint) {
+	allowed, moved := gm.tutor.interact(gm.logic, pick)
+	if !allowed {
+		gm.showToast(gm.tutor.hint()) // remind the player.
+		return
+	}
+	if moved {
+		gm.showToast(gm.tutor.hint()) // the next step, or done.
+	}
+	if gm.tutor.done() {
+		gm.endTutorial()
+	}
+	if moved {
+		gm.anim = animateCardMoves(gm, gm.logic.PreviousBoard())
+		return
+	}
+	gm.redrawBoard()
+}
+
+// endTutorial stops guiding the player, who is free to continue the game.
+func (gm *game) endTutorial() {
+	gm.tutor = nil
+	gm.save.TutorialDone = true
+	gm.save.persist()
+}
+
+// gameProgress is the progress that is lost by leaving a game.
+type gameProgress struct {
+	moves       int // moves played, counting undos as effort.
+	foundations int // cards on the foundations.
+}
+
+// progressOf returns the progress of the game being played.
+func progressOf(l *logic) gameProgress {
+	p := gameProgress{moves: l.MoveCount()}
+	for _, stack := range l.Foundations() {
+		p.foundations += len(stack)
+	}
+	return p
+}
+
+// leaving a game is confirmed once either threshold is reached.
+const (
+	confirmMoves       = 15 // about the moves needed to free the first aces.
+	confirmFoundations = 8  // about two ranks of each suit.
+)
+
+// shouldConfirmNewGame returns true if leaving a game with the given
+// progress needs confirming. Fresh games are left without asking so that
+// browsing deals isn't a nag, while deep games are protected from an
+// accidental new game.
+func shouldConfirmNewGame(p gameProgress) bool {
+	return p.moves >= confirmMoves || p.foundations >= confirmFoundations
+}
+
+// confirmNewGame returns true if the current game can be left for a new
+// game. Leaving a game in progress needs a second press, so the first
+// press asks for confirmation and returns false. Won games are left
+// without asking.
+func (gm *game) confirmNewGame(now time.Time) bool {
+	if gm.gameOver || !shouldConfirmNewGame(progressOf(gm.logic)) || now.Before(gm.leaveExpires) {
+		gm.leaveExpires = time.Time{}
+		return true
+	}
+	gm.leaveExpires = now.Add(2 * toastDuration)
+	gm.showToast("leave this game? press again")
+	return false
+}
+
+// quitPress returns true if a quit key press quits the game. When quitting
+// is confirmed the first press returns false along with the time that the
+// second press must come before. Later presses start over.
+func quitPress(confirm bool, now, expires time.Time) (quit bool, next time.Time) {
+	if !confirm || now.Before(expires) {
+		return true, time.Time{}
+	}
+	return false, now.Add(2 * toastDuration)
+}
+
+// resetPrompt returns the player message for the given number of reset
+// presses and true once the reset has been confirmed.
+func resetPrompt(presses int) (msg string, reset bool) {
+	switch {
+	case presses <= 1:
+		return "reset scores? press R", false
+	case presses == 2:
+		return "really reset? press R", false
+	}
+	return "scores reset", true
+}
+
+// bookmark saves the current game position so it can be retried later.
+func (gm *game) bookmark() {
+	if gm.logic.custom {
+		gm.showToast("custom games can't be bookmarked")
+		return
+	}
+	name := bookmarkName(gm.save.Seed, gm.logic.MoveNumber())
+	gm.save.persistBookmark(name, gm.logic.Bookmark())
+	gm.showToast("bookmarked " + name)
+}
+
+// restoreBookmark returns to the furthest bookmarked position
+// of the current game.
+func (gm *game) restoreBookmark() {
+	name, ok := gm.save.furthestBookmark(gm.save.Seed)
+	if !ok {
+		gm.showToast("no bookmarks")
+		return
+	}
+	if err := gm.logic.RestoreBookmark(gm.save.Bookmarks[name]); err != nil {
+		slog.Warn("ignoring bookmark", "name", name, "error", err)
+		gm.showToast("bad bookmark " + name)
+		return
+	}
+	gm.recordText("restore", uint(gm.logic.moves.undos), fmt.Sprint(gm.logic.History()))
+	gm.gameStart = time.Now()
+	gm.lastInput, gm.activeTime = gm.gameStart, 0
+	gm.updateInfo()
+	gm.redrawBoard()
+	gm.showToast("restored " + name)
+}
+
+// trackInput accumulates the active play time on each player input.
+func (gm *game) trackInput(now time.Time) {
+	gm.activeTime += activeElapsed(now, gm.lastInput, idleTime)
+	gm.lastInput = now
+}
+
+// gameClock returns the time spent playing the current game. This is
+// either the wall clock time or the active time which pauses when the
+// player is idle.
+func (gm *game) gameClock(now time.Time) time.Duration {
+	if !gm.save.ActiveClock {
+		return now.Sub(gm.gameStart)
+	}
+	return gm.activeTime + activeElapsed(now, gm.lastInput, idleTime)
+}
+
+// isIdle returns true if the game is not busy and there has been no
+// player input for the idle frame delay.
+func isIdle(now, lastInput time.Time, busy bool) bool {
+	return !busy && now.Sub(lastInput) >= idleFrameDelay
+}
+
+// frameLimit returns the engine frame rate cap: the player cap while
+// active, and the slowest engine rate when idle. Player caps outside
+// the engine range are treated as no cap.
+func frameLimit(playerLimit int, idle bool) int {
+	switch {
+	case idle:
+		return idleFrameLimit
+	case playerLimit < idleFrameLimit || playerLimit > maxFrameLimit:
+		return maxFrameLimit
+	}
+	return playerLimit
+}
+
+// activeElapsed returns the play time since the last input. The time
+// stops counting once the player has been idle for the idle threshold.
+func activeElapsed(now, lastInput time.Time, idleThreshold time.Duration) time.Duration {
+	return max(0, min(now.Sub(lastInput), idleThreshold))
+}
+
+// clockText is the game clock display text, ie: "time 4:05".
+func clockText(clock time.Duration) string {
+	secs := int(clock.Seconds())
+	return fmt.Sprintf("time %d:%02d", secs/60, secs%60)
+}
+
+// attemptText is the attempt counter shown when a repeated game is dealt,
+// ie: "attempt 3".
+func attemptText(attempt uint) string { return fmt.Sprintf("attempt %d", attempt) }
+
+// autoMoveAfterMove auto moves one safe card to the foundation if the
+// player auto moves cards after each move. Returns true if a card moved.
+func (gm *game) autoMoveAfterMove() bool {
+	if gm.save.AutoPlay == autoPlayOff || gm.save.AutoPlay == autoPlayOnDemand || gm.tutor != nil {
+		return false
+	}
+	if !gm.logic.AutoMoveCard() {
+		return false
+	}
+	gm.record("auto", 0)
+	return true
+}
+
+// startChain starts continuing the player move if it sent a card to a
+// foundation and the player continues foundation moves up the suit.
+func (gm *game) startChain() {
+	gm.chainCard, gm.chaining = foundationMove(gm.logic.PreviousBoard(), gm.logic.Board())
+	gm.chaining = gm.chaining && gm.save.SuitChain && gm.tutor == nil
+}
+
+// continueChain sends the next card of the suit being continued.
+// Returns true if a card moved.
+func (gm *game) continueChain() bool {
+	if !gm.chaining {
+		return false
+	}
+	next, ok := gm.logic.sendNextInSuit(gm.chainCard)
+	if !ok {
+		gm.chaining = false
+		return false
+	}
+	gm.record("chain", gm.chainCard)
+	gm.chainCard = next
+	return true
+}
+
+// finishChain sends the rest of the suit being continued
+// without animating.
+func (gm *game) finishChain() {
+	if gm.chaining {
+		gm.record("chainAll", gm.chainCard)
+		gm.logic.SendSuitChain(gm.chainCard)
+		gm.chaining = false
+	}
+}
+
+// autoComplete finishes the game immediately when it is certain to be
+// won, rather than auto moving the cards one by one. The player must
+// auto move cards, either after each move or on request.
+// Returns true if the game was completed.
+func (gm *game) autoComplete() bool {
+	if gm.save.AutoPlay == autoPlayOff || gm.tutor != nil {
+		return false
+	}
+	if !gm.logic.AutoComplete() {
+		return false
+	}
+	gm.record("complete", 0)
+	return true
+}
+
+// gradeWin grades the winning move count against the solver solution.
+// The first time a seed is won the solver runs in the background and the
+// grade is shown when it finishes. No grade is shown if the solver runs
+// out of time.
+func (gm *game) gradeWin(seed uint, score int) {
+	if gm.logic.closedCells > 0 {
+		return // the solver plays with all the free cells.
+	}
+	gm.gradeScore = score
+	variant := gm.save.variant() // solutions differ between variants.
+	if length, ok := gm.save.solverMoves(variant)[seed]; ok {
+		gm.grade = efficiencyGrade(score, length)
+		return
+	}
+	if gm.solved != nil {
+		return // still solving a previous game.
+	}
+	deal, single := gm.logic.moves.stack[0], gm.logic.singleCardMoves
+	solved := make(chan solveResult, 1)
+	go func() {
+		length, ok := solveLength(deal, single, solveBudget)
+		solved <- solveResult{seed: seed, variant: variant, length: length, ok: ok}
+	}()
+	gm.solved = solved
+}
+
+// backgroundTicker returns the seconds passed to the background shader.
+// The background stops moving once the game is won.
+func backgroundTicker(now, gameStart, wonAt time.Time) float64 {
+	if !wonAt.IsZero() && now.After(wonAt) {
+		now = wonAt
+	}
+	return now.Sub(gameStart).Seconds()
+}
+
+// checkUndo starts checking if the last move should be undone.
+// The solver searches run in the background and the result is shown
+// by Update. Checks that don't need the solver are shown immediately.
+func (gm *game) checkUndo() {
+	if gm.undoChecked != nil {
+		return // still checking.
+	}
+	l := gm.logic
+	if l.closedCells > 0 || l.MoveNumber() == 0 {
+		gm.showToast(undoCheckText(l.UndoWouldHelp()))
+		return
+	}
+	previous, board, single := l.PreviousBoard(), l.Board(), l.singleCardMoves
+	checked := make(chan undoCheckResult, 1)
+	go func() {
+		helps, computed := undoWouldHelp(previous, board, single)
+		checked <- undoCheckResult{board: board, helps: helps, computed: computed}
+	}()
+	gm.undoChecked = checked
+}
+
+// undoCheckText describes the result of checking the last move.
+func undoCheckText(helps, computed bool) string {
+	switch {
+	case !computed:
+		return "can't tell, keep going"
+	case helps:
+		return "undo the last move"
+	}
+	return "no need to undo"
+}
+
+// autoMoveOnDemand moves all the safe cards to the foundations if the
+// player auto moves cards on request. Returns the number of moved cards.
+func (gm *game) autoMoveOnDemand() int {
+	if gm.save.AutoPlay != autoPlayOnDemand {
+		return 0
+	}
+	gm.record("autoAll", 0)
+	return gm.logic.AutoMoveAll()
+}
+
+// isFoundationPick returns true if the pick is a foundation pile or the
+// top card of a foundation.
+func isFoundationPick(board [52]uint, pick uint) bool {
+	switch {
+	case pick >= EMPTY_PILE1+FC && pick <= EMPTY_PILE1+FS:
+		return true
+	case pick >= AC && pick <= KS:
+		return board[pick] >= FC && board[pick] <= FS
+	}
+	return false
+}
+
+// nextVariant switches to the next variant and redeals the game. Variants
+// only change between games so that each score is played under one set
+// of rules.
+func (gm *game) nextVariant() {
+	if gm.anim != nil || gm.state != PlayState || gm.tutor != nil {
+		return
+	}
+	if gm.logic.MoveNumber() > 0 && !gm.gameOver {
+		gm.showToast("finish the game first")
+		return
+	}
+	next := variants[(slices.Index(variants, gm.save.variant())+1)%len(variants)]
+	gm.save.setVariant(next)
+	gm.save.persist()
+	gm.logic.singleCardMoves, gm.logic.closedCells = variantRules(next)
+	gm.resetBoard()
+	gm.showToast(next)
+}
+
+// importLayout plays the layout pasted into the data directory import
+// file. A layout that matches a classic deal plays that game so that its
+// scores are kept, any other layout is played as a custom game.
+func (gm *game) importLayout() {
+	if gm.anim != nil || gm.state != PlayState || gm.tutor != nil || gm.save.seedFixed() {
+		return
+	}
+	file, layout, err := gm.save.importLayout()
+	if err != nil {
+		slog.Warn("layout import", "file", file, "err", err)
+		gm.showToast("no layout to import")
+		return
+	}
+	if _, ok := parseLayout(layout); !ok {
+		gm.showToast("invalid layout")
+		return
+	}
+	if !gm.confirmNewGame(time.Now()) {
+		return
+	}
+	if seed, ok := gm.logic.SeedForLayout(layout); ok {
+		gm.save.Seed = seed
+		gm.save.persistSeed(seed)
+		gm.resetBoard()
+		gm.showToast(fmt.Sprintf("layout is game %d", seed))
+		return
+	}
+	gm.layout = layout
+	gm.resetBoard()
+}
+
+// rankedDeal checks a deal before it starts in ranked play. Unsolvable
+// deals can still be played, but they are unranked so that leaving them
+// can't break the win streak. Returns a warning for unranked deals.
+func rankedDeal(ranked, solvable bool) (unranked bool, warning string) {
+	if ranked && !solvable {
+		return true, "unsolvable deal, not ranked"
+	}
+	return false, ""
+}
+
+// reset the game to the default deal.
+func (gm *game) resetBoard() {
+	previousBoard := gm.logic.Board()
+	sinceDeal := time.Since(gm.gameStart)
+	gm.redraw = true
+	solvable, warning := gm.dealGame()
+	gm.unsolvable.Cull(solvable)
+	if warning != "" {
+		gm.showToast(warning)
+	} else if gm.save.RepeatUntilWon {
+		gm.showToast(attemptText(gm.save.attempt(gm.save.Seed)))
+	}
+
+	// generate a color for the board shader.
+	r, g, b := boardColor(gm.save.Seed, gm.save.HighContrast)
+	gm.board.SetColor(r, g, b, 1.0)
+
+	// generate a random faction based on the seed.
+	gm.seed01 = gameSeedToFrac(gm.save.Seed)
+
+	// update the stats
+	gm.updateInfo()
+
+	// animate the cards to the new positions.
+	if gm.anim = gm.dealAnimation(previousBoard, sinceDeal); gm.anim == nil {
+		gm.redrawBoard()
+	}
+}
+
+// dealGame deals the current seed, or the imported layout, recording
+// the game that was left.
+// Returns if the deal is solvable and any ranked play warning.
+func (gm *game) dealGame() (solvable bool, warning string) {
+
+	// leaving a game that was started but not won breaks the win streak.
+	// Unranked games only count as an attempt while they are repeated.
+	if gm.logic.moves != nil && gm.logic.MoveNumber() > 0 && !gm.gameOver && !gm.logic.custom {
+		switch {
+		case !gm.unranked:
+			gm.save.recordLoss(gm.logic.gameSeed)
+			gm.save.persist()
+		case gm.save.RepeatUntilWon:
+			gm.save.attempts()[gm.logic.gameSeed] += 1
+			gm.save.persist()
+		}
+	}
+	gm.save.persistInProgress(false)
+	if gm.tutor != nil {
+		gm.endTutorial() // leaving the tutorial game skips the tutorial.
+	}
+	gm.seeds.cancel() // the seed was changed directly.
+	gm.stuckRedeal = time.Time{}
+	if gm.layout != "" && gm.logic.NewCustomGame(gm.layout) {
+		gm.recordText("custom", 0, gm.save.variant()+" "+gm.layout)
+		solvable, warning = true, "custom game, not scored"
+		gm.unranked = true
+	} else {
+		gm.logic.NewGame(gm.save.Seed)
+		gm.recordText("seed", gm.save.Seed, gm.save.variant())
+		solvable = gm.logic.IsGameSolvable(gm.save.Seed)
+		gm.unranked, warning = rankedDeal(gm.save.RankedPlay, solvable)
+	}
+	gm.layout = ""
+	gm.gameStart = time.Now()
+	gm.lastInput, gm.activeTime = gm.gameStart, 0
+	gm.gameOver = false
+	gm.wonAt = time.Time{}
+	gm.chaining = false
+	gm.clearTrails() // the deal replaces any card move animation.
+	return solvable, warning
+}
+
+// stuckRedealDue returns true when a stuck repeated game scheduled for
+// a redeal at the given time should be dealt again. The redeal waits
+// for any running animation, such as an undo.
+func stuckRedealDue(at, now time.Time, animating bool) bool {
+	return !at.IsZero() && !animating && !now.Before(at)
+}
+
+// dealAnimation returns the animation from the previous board to the
+// current deal, or nil if the cards should snap to the deal. Cards snap
+// when the board is unchanged, when new games are being dealt rapidly,
+// or when motion is reduced.
+func (gm *game) dealAnimation(previousBoard [52]uint, sinceDeal time.Duration) Animation {
+	unchanged := previousBoard == gm.logic.Board()
+	if unchanged || sinceDeal < dealRepeatTime || gm.save.ReduceMotion {
+		return nil
+	}
+	if gm.save.DealCards {
+		return animateDeal(gm)
+	}
+	return animateCardMoves(gm, previousBoard)
+}
+
+// redrawBoard redraws the current board state.
+func (gm *game) redrawBoard() {
+	gm.updateInfo() // update score.
+
+	// place the cards, only updating the cards that changed.
+	states := gm.boardStates()
+	for cid := range states {
+		if gm.ghosted[cid] {
+			gm.ghosts[cid].Cull(true)
+			gm.ghosted[cid] = false
+		}
+		drawCard(gm.cards[cid], &gm.drawn[cid], states[cid], gm.redraw)
+		drawCard(gm.shadows[cid], &gm.shadowDrawn[cid], shadowState(states[cid], gm.save.CardShadows), gm.redraw)
+	}
+	gm.redraw = false
+
+	gm.hoverPick = NO_HIT // redo any hover highlight.
+
+	// color the empty piles, ie: the free cells needed to move a
+	// selected sequence, or the next tutorial pile.
+	for pid := range gm.piles {
+		r, g, b := gm.pileColor(uint(pid))
+		gm.piles[pid].SetColor(r, g, b, 1)
+	}
+
+	for suit := CLB; suit <= SPD; suit++ {
+		gm.done[suit].Cull(!gm.logic.FoundationComplete(suit))
+	}
+}
+
+// boardStates returns how each card is drawn for the current board,
+// including the selection, the tutorial hint, and the ace blockers.
+func (gm *game) boardStates() [52]cardState {
+	selected := gm.logic.GetSelected()
+	highlight := highlightRGB(gm.save.HighlightColor)
+	states := cardStates(gm.logic.Board(), selected, gm.save.TiltCards, highlight)
+
+	// show the tutorial player where to tap next.
+	if gm.tutor != nil {
+		if pick := gm.tutor.expected(gm.logic); isCard(pick) {
+			states[pick].r, states[pick].g, states[pick].b = 0.6, 0.8, 1.0
+		}
+	}
+
+	// show beginners the aces and the cards burying them.
+	if gm.showBlockers {
+		aces, blockers := gm.logic.AceBlockers()
+		for _, cid := range aces {
+			states[cid].r, states[cid].g, states[cid].b = 0.6, 1.0, 0.6
+		}
+		for _, cid := range blockers {
+			states[cid].r, states[cid].g, states[cid].b = 1.0, 0.6, 0.6
+		}
+	}
+	return states
+}
+
+// pileColor returns the color of the given empty pile for the current
+// board, ignoring any hover highlight.
+func (gm *game) pileColor(pid uint) (r, g, b float64) {
+	r, g, b = 1, 1, 1
+	switch {
+	case pid >= FC && pid <= FS:
+		r, g, b = foundationTint(pid) // hint at the foundation suit.
+	case pid < FC && !gm.logic.isOpenFreecell(pid):
+		r, g, b = 0.3, 0.3, 0.3 // closed by the variant.
+	case pid < FC && slices.Contains(gm.logic.reservedFreecells(), pid):
+		highlight := highlightRGB(gm.save.HighlightColor)
+		r, g, b = highlight[0], highlight[1], highlight[2]
+	}
+	if gm.tutor != nil && gm.tutor.expected(gm.logic) == EMPTY_PILE1+pid {
+		r, g, b = 0.6, 0.8, 1.0 // the next tutorial pile.
+	}
+	return r, g, b
+}
+
+// updateInvite bobs a suggested card once the player has been idle for
+// a while, and puts it back as soon as there is any input.
+func (gm *game) updateInvite(now time.Time) {
+	busy := gm.anim != nil || gm.state != PlayState || gm.gameOver ||
+		gm.tutor != nil || gm.logic.isSelectionActive()
+	if !gm.save.IdleInvite || gm.save.ReduceMotion || !isInviteIdle(now, gm.lastInput, busy) {
+		for i, cid := range gm.invite {
+			drawCard(gm.cards[cid], &gm.drawn[cid], gm.inviteAt[i], false)
+		}
+		gm.invite, gm.inviteAt = nil, nil
+		return
+	}
+	if gm.invite == nil {
+		move, ok := gm.logic.Hint()
+		if !ok {
+			return
+		}
+		gm.invite = gm.logic.getSequence(move[0])
+		for _, cid := range gm.invite {
+			gm.inviteAt = append(gm.inviteAt, gm.drawn[cid])
+		}
+	}
+	bob := inviteOffset(now.Sub(gm.lastInput))
+	for i, cid := range gm.invite {
+		state := gm.inviteAt[i]
+		state.y += bob
+		drawCard(gm.cards[cid], &gm.drawn[cid], state, false)
+	}
+}
+
+// isInviteIdle returns true if the game is not busy and the player has
+// been idle long enough to invite a move.
+func isInviteIdle(now, lastInput time.Time, busy bool) bool {
+	return !busy && now.Sub(lastInput) >= idleInviteDelay
+}
+
+// inviteOffset returns how far the invited card is raised after the given
+// idle time. The card starts at rest and smoothly rises and falls.
+func inviteOffset(idle time.Duration) float64 {
+	phase := float64(idle-idleInviteDelay) / float64(invitePeriod)
+	return inviteBob * 0.5 * (1 - math.Cos(2*math.Pi*phase))
+}
+
+// safeHints returns the cards that the last move made safe to send to
+// the foundations, so they can be pulsed as a hint. There are no hints
+// when cards are auto moved after each move, since the safe cards are
+// about to move anyway.
+func (gm *game) safeHints(from [52]uint, enabled bool) []uint {
+	if !enabled || !gm.save.SafeHints || gm.save.AutoPlay == autoPlayOnMove || gm.tutor != nil {
+		return nil
+	}
+	return newlySafeCards(from, gm.logic.Board())
+}
+
+// startPulse highlights the given cards, finishing any previous highlight.
+func (gm *game) startPulse(cards []uint) {
+	gm.updatePulse(gm.pulseStart.Add(pulseDuration))
+	gm.pulse, gm.pulseStart = cards, time.Now()
+	gm.updatePulse(gm.pulseStart)
+}
 
-			// update the best score.
-			if bestScore, ok := gm.save.Scores[gm.save.Seed]; ok {
-				if score < bestScore {
-					gm.save.Scores[gm.save.Seed] = score
-					gm.save.persist()
-				}
-			} else {
-				gm.save.Scores[gm.save.Seed] = score
-				gm.save.persist()
-			}
-			gm.updateInfo()
-			gm.anim = animateGameComplete(gm)
+// updatePulse fades the moved card highlight back to the card color.
+// Selected cards keep the selection color.
+func (gm *game) updatePulse(now time.Time) {
+	if len(gm.pulse) == 0 {
+		return
+	}
+	fade := min(1.0, float64(now.Sub(gm.pulseStart))/float64(pulseDuration))
+	for _, cid := range gm.pulse {
+		if gm.logic.isSelected(cid) {
+			continue
 		}
+		state := gm.drawn[cid]
+		state.r, state.g, state.b = lerp(0.6, 1, fade), lerp(0.8, 1, fade), 1.0
+		drawCard(gm.cards[cid], &gm.drawn[cid], state, false)
 	}
-
-	// wait for the font to load before the initial text update.
-	// Afterwards only need to update if it changes.
-	if !gm.infoInit {
-		gm.infoInit = gm.updateInfo()
+	if fade >= 1 {
+		gm.pulse = nil
 	}
 }
 
-// reset the game to the default deal.
-func (gm *game) resetBoard() {
-	previousBoard := gm.logic.Board()
-	gm.logic.NewGame(gm.save.Seed)
-	gm.unsolvable.Cull(gm.logic.IsGameSolvable(gm.save.Seed))
-	gm.gameStart = time.Now()
-	gm.gameOver = false
+// cardEntity is the card model calls used to draw a card.
+type cardEntity interface {
+	SetAt(x, y, z float64) *vu.Entity
+	SetAa(x, y, z, angleInRadians float64) *vu.Entity
+	SetColor(r, g, b, a float64) *vu.Entity
+	Cull(culled bool)
+}
 
-	// generate a color for the board shader.
-	r, g, b := gameColor(gm.save.Seed)
-	gm.board.SetColor(r, g, b, 1.0)
+// cardState is the drawn location and color of a card.
+type cardState struct {
+	x, y, z, tilt float64 // location and tilt.
+	r, g, b, a    float64 // color and opacity.
+	culled        bool    // true for hidden cards.
+	valid         bool    // false if the card entity may not match.
+}
 
-	// generate a random faction based on the seed.
-	gm.seed01 = gameSeedToFrac(gm.save.Seed)
+// cardStates returns how each card is drawn for the given board,
+// where selected cards are drawn in the highlight color.
+func cardStates(board [52]uint, selected []uint, tilted bool, highlight [3]float64) (states [52]cardState) {
+	for cid, bid := range board {
+		if bid >= HIDDEN_CARD {
+			states[cid] = cardState{culled: true, valid: true}
+			continue
+		}
+		x, y, z := placeCard(bid)
+		tilt := cardTilt(bid, tilted)
+		states[cid] = cardState{x: x, y: y, z: z, tilt: tilt, r: 1, g: 1, b: 1, a: 1, valid: true}
+	}
+	for _, cid := range selected {
+		states[cid].r, states[cid].g, states[cid].b = highlight[0], highlight[1], highlight[2]
+	}
+	return states
+}
 
-	// update the stats
-	gm.updateInfo()
+// card shadows are drawn down and to the right of each card, between the
+// card and the card it overlaps in the cascade.
+const (
+	shadowDx, shadowDy, shadowDz = 0.03, -0.04, -0.0005
+	shadowShade                  = 0.0  // shadow color.
+	shadowAlpha                  = 0.35 // shadow opacity.
+	shadowShader                 = "shd:card"
+)
 
-	// animate the cards to the new positions.
-	gm.anim = animateCardMoves(gm, previousBoard)
+// shadowState returns how the shadow for the given card is drawn.
+// Shadows are hidden for hidden cards or if shadows are not enabled.
+func shadowState(card cardState, enabled bool) cardState {
+	if card.culled || !enabled {
+		return cardState{culled: true, valid: true}
+	}
+	s := card
+	s.x, s.y, s.z = card.x+shadowDx, card.y+shadowDy, card.z+shadowDz
+	s.r, s.g, s.b, s.a = shadowShade, shadowShade, shadowShade, shadowAlpha
+	return s
 }
 
-// redrawBoard redraws the current board state.
-func (gm *game) redrawBoard() {
-	gm.updateInfo() // update score.
+// hideShadow hides the card shadow while the card is animated.
+// The shadow is redrawn with the board.
+func (gm *game) hideShadow(cid uint) {
+	drawCard(gm.shadows[cid], &gm.shadowDrawn[cid], cardState{culled: true, valid: true}, false)
+}
 
-	// place the cards.
-	for cid, bid := range gm.logic.Board() {
-		gm.cards[cid].SetColor(1, 1, 1, 1)
-		gm.cards[cid].Cull(false)
-		if bid >= HIDDEN_CARD {
-			gm.cards[cid].Cull(true)
-		} else {
-			x, y, z := placeCard(bid)
-			gm.cards[cid].SetAt(x, y, z)
+// drawCard updates the card entity with the parts of the next card state
+// that differ from the last drawn state. Everything is updated if forced
+// or if the last drawn state is not valid. Hidden cards keep their last
+// location and color.
+func drawCard(card cardEntity, last *cardState, next cardState, force bool) {
+	force = force || !last.valid
+	if next.culled {
+		if force || !last.culled {
+			card.Cull(true)
+			last.culled, last.valid = true, true
 		}
+		return
 	}
-
-	// highlight any selected cards.
-	selected := gm.logic.GetSelected()
-	sr, sg, sb := 1.0, 0.8, 0.0
-	for _, cid := range selected {
-		gm.cards[cid].SetColor(sr, sg, sb, 1)
+	if force || last.culled {
+		card.Cull(false)
 	}
+	if force || last.x != next.x || last.y != next.y || last.z != next.z {
+		card.SetAt(next.x, next.y, next.z)
+	}
+	if force || last.tilt != next.tilt {
+		card.SetAa(1, 0, 0, next.tilt)
+	}
+	if force || last.r != next.r || last.g != next.g || last.b != next.b || last.a != next.a {
+		card.SetColor(next.r, next.g, next.b, next.a)
+	}
+	*last = next
 }
 
 // updateInfo updates the game text.
 func (gm *game) updateInfo() bool {
-	line := 56.0 // pixel spacing between text lines.
+	line := 56.0 * float64(gm.textDensity) // pixel spacing between text lines.
+	font := textFont(gm.textDensity)
 
 	// get the scores
 	score := fmt.Sprintf("%03d", gm.logic.MoveCount())
 	prevScore := "---"
-	if ps, ok := gm.save.Scores[gm.save.Seed]; ok {
+	if ps, ok := gm.save.scores()[gm.save.Seed]; ok {
 		prevScore = fmt.Sprintf("%03d", ps)
 	}
 
 	// update the game score and seed
 	draw.Draw(gm.text, gm.text.Bounds(), image.Transparent, image.Point{}, draw.Src)
-	e1 := gm.scores.WriteImageText("hack48", score, 0, int(line*0), gm.text)
-	e2 := gm.scores.WriteImageText("hack48", prevScore, 0, int(line*1.34), gm.text)
+	e1 := gm.scores.WriteImageText(font, score, 0, int(line*0), gm.text)
+	e2 := gm.scores.WriteImageText(font, prevScore, 0, int(line*1.34), gm.text)
 	gm.scores.UpdateTexture(gm.eng, gm.text)
-	e3 := gm.updateGameSeed(fmt.Sprintf("%06d", gm.save.Seed))
+	e3 := gm.updateGameSeed(fmt.Sprintf("%06d", gm.seeds.shown(gm.save.Seed)))
+
+	// update the optional move number.
+	var e4 error
+	gm.moveLabel.show(gm.save.ShowMoveNumber)
+	if gm.save.ShowMoveNumber {
+		e4 = gm.moveLabel.write(gm.eng, moveNumberText(gm.logic.MoveNumber()))
+	}
+
+	// update the optional statistics.
+	var e5 error
+	gm.statsHUD.show(gm.save.ShowStats)
+	if gm.save.ShowStats {
+		e5 = gm.statsHUD.write(gm.eng, statsText(gm.save.Stats, gm.save.TotalPlayTime))
+	}
+
+	// update the optional cards remaining.
+	var e6 error
+	gm.remaining.show(gm.save.ShowRemaining)
+	if gm.save.ShowRemaining {
+		e6 = gm.remaining.write(gm.eng, remainingText(cardsRemaining(gm.logic.Foundations())))
+	}
+
+	// update the optional move log.
+	var e8 error
+	gm.moveLog.show(gm.save.ShowMoveLog)
+	if gm.save.ShowMoveLog {
+		e8 = gm.moveLog.write(gm.eng, moveLogText(gm.logic.moves.stack, moveLogRows)...)
+	}
+
+	// show the par score for popular classic deals.
+	var e9 error
+	par, hasPar := parScore(gm.save.Seed)
+	hasPar = hasPar && gm.save.variant() == variantClassic
+	gm.par.show(hasPar)
+	if hasPar {
+		e9 = gm.par.write(gm.eng, parText(par))
+	}
+
+	// warn while progress is not being saved.
+	var e7 error
+	gm.saveWarning.show(gm.save.saveErr != nil)
+	if gm.save.saveErr != nil {
+		e7 = gm.saveWarning.write(gm.eng, saveWarningText)
+	}
 
 	// return true if all the info was updated.
 	// Expect false if the font is not yet loaded.
-	return e1 == nil && e2 == nil && e3 == nil
+	return e1 == nil && e2 == nil && e3 == nil && e4 == nil && e5 == nil && e6 == nil && e7 == nil && e8 == nil && e9 == nil
+}
+
+// statsText is the game statistics display text,
+// including the lifetime play time in hours and minutes.
+func statsText(stats Stats, played time.Duration) string {
+	mins := int(played.Minutes())
+	return fmt.Sprintf("W:%d Streak:%d %dh%02dm", stats.Wins, stats.Streak, mins/60, mins%60)
+}
+
+// moveNumberText is the move number display text.
+func moveNumberText(move int) string {
+	return fmt.Sprintf("move %d", move)
+}
+
+// cardsRemaining returns the number of cards that are not yet on
+// the foundations. Foundations are built in rank order, so each
+// foundation holds one card for each rank up to its top card.
+func cardsRemaining(foundations [4][]uint) int {
+	remaining := 52
+	for _, stack := range foundations {
+		if len(stack) > 0 {
+			remaining -= int(deck[stack[len(stack)-1]].Rank) + 1
+		}
+	}
+	return remaining
+}
+
+// moveLogRows is the number of recent moves in the move log.
+const moveLogRows = 5
+
+// moveLogText returns up to rows descriptions of the latest moves
+// in the given board positions, oldest first, ie: "5H->6S", "AC->F".
+func moveLogText(boards [][52]uint, rows int) (lines []string) {
+	for i := max(1, len(boards)-rows); i < len(boards); i++ {
+		lines = append(lines, describeMove(boards[i-1], boards[i]))
+	}
+	return lines
+}
+
+// saveWarningText is shown while the save file can't be written.
+const saveWarningText = "progress is not being saved"
+
+// remainingText is the cards remaining display text.
+func remainingText(remaining int) string {
+	return fmt.Sprintf("%d left", remaining)
 }
 
 // update the game seed
 func (gm *game) updateGameSeed(gameSeed string) (err error) {
 	draw.Draw(gm.text, gm.text.Bounds(), image.Transparent, image.Point{}, draw.Src)
-	err = gm.number.WriteImageText("hack48", gameSeed, 0, 0, gm.text)
+	err = gm.number.WriteImageText(textFont(gm.textDensity), gameSeed, 0, 0, gm.text)
 	gm.number.UpdateTexture(gm.eng, gm.text)
 	return err
 }
 
+// showToast briefly displays a single line message.
+func (gm *game) showToast(msg string) {
+	if err := gm.toast.write(gm.eng, msg); err != nil {
+		slog.Debug("toast text", "err", err)
+		return
+	}
+	gm.toast.show(true)
+	gm.toastEnd = time.Now().Add(toastDuration)
+}
+
+// undo reverts the last move and tells the player what was undone.
+func (gm *game) undo() {
+	before := gm.logic.Undo()
+	gm.record("undo", 0)
+	if gm.save.AnimateUndo && !gm.save.ReduceMotion && gm.anim == nil {
+		gm.anim = animateUndo(gm, before)
+	} else {
+		gm.redrawBoard()
+	}
+	if msg := describeMove(gm.logic.Board(), before); msg != "" && gm.save.UndoToast {
+		gm.showToast("undid " + msg)
+	}
+}
+
 // process a player click.
 func (gm *game) handleCardClick() {
-	pick := gm.hitCard(gm.scene.Cam(), gm.ww, gm.wh, gm.mx, gm.my)
+	pick := hitCard(gm.scene.Cam(), gm.logic.Board(), gm.save.TiltCards, gm.ww, gm.wh, gm.mx, gm.my)
+	if gm.tutor == nil {
+		gm.record("pick", pick) // tutorial picks are gated, so aren't replayable.
+	}
 	switch {
+	case gm.tutor != nil && pick != NO_HIT:
+		gm.tutorialClick(pick)
+	case gm.save.AutoPlay == autoPlayOnDemand && len(gm.logic.GetSelected()) == 0 && isFoundationPick(gm.logic.Board(), pick):
+		// tapping a foundation auto moves cards for players without a keyboard.
+		gm.dispatch(nil, autoMoveIntent)
 	case pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16:
 		if gm.logic.Interact(pick) {
+			gm.save.persistInProgress(true)
+			gm.startChain()
 			gm.anim = animateCardMoves(gm, gm.logic.PreviousBoard())
 			return
 		}
 		gm.redrawBoard()
 	case pick >= AC && pick <= KS:
 		if gm.logic.Interact(pick) {
+			gm.save.persistInProgress(true)
+			gm.startChain()
 			gm.anim = animateCardMoves(gm, gm.logic.PreviousBoard())
 			return
 		}
 		gm.redrawBoard()
-	case pick >= HIDDEN_CARD:
-		gm.logic.clearSelected() // remove selection.
+	case pick == NO_HIT:
+		gm.logic.clearSelected() // clicked empty space: remove selection.
 		gm.redrawBoard()
 	default:
 		slog.Error("not possible: dev error")
 	}
 }
 
+// dispatch performs the action for a player intent.
+func (gm *game) dispatch(eng *vu.Engine, it intent) {
+	switch it {
+	case quitIntent:
+		var quit bool
+		quit, gm.quitExpires = quitPress(gm.save.ConfirmQuit, time.Now(), gm.quitExpires)
+		if !quit {
+			gm.showToast("press Q again to quit")
+			return
+		}
+		gm.save.persist() // keep the latest play time.
+		eng.Shutdown()
+	case fullscreenIntent:
+		eng.ToggleFullscreen()
+		gm.save.Full = !gm.save.Full
+		gm.save.persistFullScreen(gm.save.Full)
+	case nextGameIntent:
+		gm.nextGame()
+	case prevGameIntent:
+		gm.prevGame()
+	case undoIntent:
+		if gm.anim == nil && !gm.gameOver && gm.state == PlayState && gm.tutor == nil {
+			gm.undo()
+		}
+	case contrastIntent:
+		gm.save.HighContrast = !gm.save.HighContrast
+		gm.save.persist()
+		r, g, b := boardColor(gm.save.Seed, gm.save.HighContrast)
+		gm.board.SetColor(r, g, b, 1.0)
+	case labelsIntent:
+		if gm.anim == nil {
+			gm.toggleCardLabels()
+		}
+	case undoCheckIntent:
+		if gm.anim == nil && !gm.gameOver && gm.state == PlayState && gm.tutor == nil {
+			gm.checkUndo()
+		}
+	case celebrateIntent:
+		gm.clearTrails()
+		gm.anim = animateGameComplete(gm)
+	case autoMoveIntent:
+		if gm.anim != nil {
+			return
+		}
+		if gm.save.AutoPlay == autoPlayOnDemand && gm.autoComplete() {
+			gm.redrawBoard() // the game is won.
+			return
+		}
+		if gm.autoMoveOnDemand() > 0 {
+			gm.redrawBoard()
+		}
+	case resetIntent:
+		gm.resetProgress(time.Now())
+	case practiceIntent:
+		// practice the most attempted game that hasn't been won.
+		if seed, ok := gm.save.hardestUnwon(); ok && seed != gm.save.Seed && !gm.save.seedFixed() && gm.confirmNewGame(time.Now()) {
+			gm.save.Seed = seed
+			gm.save.persistSeed(seed)
+			gm.resetBoard()
+		}
+	case hardGameIntent:
+		// deal a random hard game. The seed is found before asking to
+		// leave the current game so a failed search doesn't use up the
+		// confirmation.
+		if gm.save.seedFixed() {
+			return
+		}
+		seed, ok := gm.logic.FindSeedByDifficulty(HardDeals)
+		if !ok {
+			gm.showToast("no hard game found")
+			return
+		}
+		if gm.confirmNewGame(time.Now()) {
+			gm.save.Seed = seed
+			gm.save.persistSeed(seed)
+			gm.resetBoard()
+		}
+	case exportIntent:
+		file, err := gm.save.exportStats()
+		if err != nil {
+			slog.Warn("stats export", "err", err)
+			gm.showToast("export failed")
+			return
+		}
+		slog.Info("stats exported", "file", file)
+		gm.showToast("stats exported")
+	case lockIntent:
+		gm.save.SeedLock = !gm.save.SeedLock
+		gm.save.persist()
+		msg := "game unlocked"
+		if gm.save.SeedLock {
+			msg = "game locked"
+		}
+		gm.showToast(msg)
+	case repeatIntent:
+		gm.save.RepeatUntilWon = !gm.save.RepeatUntilWon
+		gm.save.persist()
+		msg := "repeat off"
+		if gm.save.RepeatUntilWon {
+			msg = "repeat until won, " + attemptText(gm.save.attempt(gm.save.Seed))
+		}
+		gm.showToast(msg)
+	case favoriteIntent:
+		msg := "favorite removed"
+		if gm.save.toggleFavorite(gm.save.Seed) {
+			msg = "favorite added"
+		}
+		gm.save.persist()
+		gm.showToast(msg)
+	case scoreboardIntent:
+		gm.openScoreboard()
+	case browseIntent:
+		gm.openBrowser()
+	case variantIntent:
+		gm.nextVariant()
+	case blockersIntent:
+		gm.showBlockers = !gm.showBlockers
+		if gm.anim == nil {
+			gm.redrawBoard()
+		}
+	case statsIntent:
+		gm.save.ShowStats = !gm.save.ShowStats
+		gm.save.persist()
+		gm.updateInfo()
+	case moveNumberIntent:
+		gm.save.ShowMoveNumber = !gm.save.ShowMoveNumber
+		gm.save.persist()
+		gm.updateInfo()
+	case importIntent:
+		gm.importLayout()
+	case remainingIntent:
+		gm.save.ShowRemaining = !gm.save.ShowRemaining
+		gm.save.persist()
+		gm.updateInfo()
+	case pileLayoutIntent:
+		gm.nextPileLayout()
+	case highlightIntent:
+		gm.nextHighlight()
+	case windowIntent:
+		// vu only sizes the window when it is created, so the
+		// default window is used from the next launch.
+		gm.save.resetWindow()
+		gm.showToast("window resets on restart")
+	case moveLogIntent:
+		gm.save.ShowMoveLog = !gm.save.ShowMoveLog
+		gm.save.persist()
+		gm.updateInfo()
+	case bookmarkIntent:
+		if gm.anim == nil && !gm.gameOver && gm.state == PlayState && gm.tutor == nil {
+			gm.bookmark()
+		}
+	case restoreIntent:
+		if gm.anim == nil && !gm.gameOver && gm.state == PlayState && gm.tutor == nil {
+			gm.restoreBookmark()
+		}
+	case pressIntent:
+		gm.handleButtonClick(gm.mx, gm.my)
+	case pickIntent:
+		gm.handleCardClick()
+	case holdIntent:
+		gm.handleButtonHold(gm.mx, gm.my, time.Since(gm.ptr.down))
+	case swipeUndoIntent:
+		// ignore swipes that start on a button, ie: the seed dial.
+		if !gm.onButton(gm.ptr.x, gm.ptr.y) {
+			gm.dispatch(eng, undoIntent)
+		}
+	}
+}
+
 // handleButtonClick checks for a player button click
 // and calls the appropriate action if a button was clicked.
 func (gm *game) handleButtonClick(mx, my int) {
@@ -531,49 +2072,114 @@ func (gm *game) handleButtonClick(mx, my int) {
 		case "prev":
 			gm.prevGame()
 		case "seed":
-			if numberpadExists {
+			if gm.numberpad() && !gm.save.seedFixed() {
 				gm.state = SelectState
 			}
 		case "undo":
-			if !gm.gameOver {
-				gm.logic.Undo()
-				gm.redrawBoard()
-			}
+			gm.dispatch(nil, undoIntent)
 		}
 		break // done since buttons don't overlap.
 	}
 }
 
-// advance the game seed and reset board.
+// advance the game seed. The board is reset once the presses stop.
+// The current game is dealt again while it is being repeated.
 func (gm *game) nextGame() {
-	if gm.save.Seed < MAX_SEED {
-		gm.save.Seed = gm.save.Seed + 1
-		gm.save.persistSeed(gm.save.Seed)
-		gm.resetBoard()
+	if gm.save.SeedLock {
+		return
+	}
+	if gm.save.RepeatUntilWon {
+		gm.seeds.change(gm.save.Seed, time.Now())
+		return
+	}
+	if seed := gm.seeds.shown(gm.save.Seed); seed < MAX_SEED {
+		gm.seeds.change(seed+1, time.Now())
+		gm.updateGameSeed(fmt.Sprintf("%06d", seed+1))
 	}
 }
 
-// reduce the game seed and reset board.
+// reduce the game seed. The board is reset once the presses stop.
+// The current game is dealt again while it is being repeated.
 func (gm *game) prevGame() {
-	if gm.save.Seed > 0 {
-		gm.save.Seed = gm.save.Seed - 1
-		gm.save.persistSeed(gm.save.Seed)
-		gm.resetBoard()
+	if gm.save.SeedLock {
+		return
+	}
+	if gm.save.RepeatUntilWon {
+		gm.seeds.change(gm.save.Seed, time.Now())
+		return
+	}
+	if seed := gm.seeds.shown(gm.save.Seed); seed > 0 {
+		gm.seeds.change(seed-1, time.Now())
+		gm.updateGameSeed(fmt.Sprintf("%06d", seed-1))
+	}
+}
+
+// seedDebounce holds back seed changes until there is a quiet period
+// so that a burst of changes deals and saves only the final seed.
+type seedDebounce struct {
+	seed    uint      // latest seed.
+	pending bool      // true if the seed is waiting to be dealt.
+	changed time.Time // when the seed last changed.
+}
+
+// change records a new seed, restarting the quiet period.
+func (sd *seedDebounce) change(seed uint, now time.Time) {
+	sd.seed, sd.pending, sd.changed = seed, true, now
+}
+
+// commit returns the pending seed once the quiet period has passed.
+// Returns false if there is no seed to deal.
+func (sd *seedDebounce) commit(now time.Time) (seed uint, ok bool) {
+	if !sd.pending || now.Sub(sd.changed) < seedQuietTime {
+		return 0, false
+	}
+	sd.pending = false
+	return sd.seed, true
+}
+
+// cancel drops any pending seed.
+func (sd *seedDebounce) cancel() { sd.pending = false }
+
+// shown returns the pending seed, or the current seed if nothing is pending.
+func (sd *seedDebounce) shown(current uint) uint {
+	if sd.pending {
+		return sd.seed
 	}
+	return current
 }
 
 // return true if the mouse is over the given button.
+// The button is checked at its current scale and location.
 func (gm *game) overButton(button *vu.Entity, mx, my int) bool {
-	px, py := float64(mx), float64(my)
 	sx, sy, _ := button.Scale()
 	cx, cy, _ := button.At()
-	hx, hy := sx*0.5, sy*0.5
+	return inButton(mx, my, cx, cy, sx, sy)
+}
+
+// onButton returns true if the given point is over any of the player buttons.
+func (gm *game) onButton(mx, my int) bool {
+	for _, button := range []*vu.Entity{gm.undoButton, gm.prevButton, gm.nextButton, gm.seedButton} {
+		if gm.overButton(button, mx, my) {
+			return true
+		}
+	}
+	return false
+}
+
+// inButton returns true if the mouse is inside the button centered
+// at cx, cy with the given width and height.
+func inButton(mx, my int, cx, cy, w, h float64) bool {
+	px, py := float64(mx), float64(my)
+	hx, hy := w*0.5, h*0.5
 	return px > cx-hx && px < cx+hx && py > cy-hy && py < cy+hy
 }
 
 // click and hold on the prev/next buttons to enter
 // a mode to quickly change the game seed using only a mouse press.
 func (gm *game) handleButtonHold(mx, my int, pressed time.Duration) {
+	if gm.save.seedFixed() {
+		return // the speed dial changes the seed.
+	}
 	if gm.overButton(gm.prevButton, mx, my) && pressed.Seconds() > holdDelay {
 		gm.seedDial = int(gm.save.Seed)
 		gm.state = DialState // start decrementing the game seed.
@@ -591,14 +2197,20 @@ func (gm *game) handleHover(mx, my int) {
 		"prev": gm.prevButton,
 		"next": gm.nextButton,
 	}
-	if numberpadExists {
+	if gm.numberpad() {
 		buttons["seed"] = gm.seedButton
 	}
 
-	// set default button color
+	// set default button color. Seed buttons are greyed out when locked.
 	for _, button := range buttons {
 		button.SetColor(1, 1, 1, 1)
 	}
+	if gm.save.SeedLock {
+		for _, button := range []*vu.Entity{gm.prevButton, gm.nextButton, gm.seedButton} {
+			button.SetColor(0.4, 0.4, 0.4, 1)
+		}
+		buttons = map[string]*vu.Entity{"undo": gm.undoButton}
+	}
 
 	// highlight color if over a button.
 	px, py := float64(mx), float64(my)
@@ -616,26 +2228,73 @@ func (gm *game) handleHover(mx, my int) {
 	}
 }
 
+// handleCardHover highlights the card or empty pile under the mouse to
+// show if the selected cards can be placed there: green if the move is
+// allowed, red if it is not.
+func (gm *game) handleCardHover() {
+	pick := hitCard(gm.scene.Cam(), gm.logic.Board(), gm.save.TiltCards, gm.ww, gm.wh, gm.mx, gm.my)
+	if pick == gm.hoverPick {
+		return // unchanged
+	}
+	gm.unhover(gm.hoverPick) // clear the previous hover highlight.
+	gm.hoverPick = pick
+	show, valid := gm.logic.dropValidity(pick)
+	if !show {
+		return
+	}
+	r, g, b := hoverColor(valid, gm.save.HighContrast)
+	switch {
+	case isCard(pick):
+		hover := gm.drawn[pick]
+		hover.r, hover.g, hover.b = r, g, b
+		drawCard(gm.cards[pick], &gm.drawn[pick], hover, false)
+	case pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16:
+		gm.piles[pick-EMPTY_PILE1].SetColor(r, g, b, 1)
+	}
+}
+
+// unhover redraws the given card or empty pile without the hover
+// highlight. Only the hovered card or pile is redrawn.
+func (gm *game) unhover(pick uint) {
+	switch {
+	case isCard(pick):
+		states := gm.boardStates()
+		drawCard(gm.cards[pick], &gm.drawn[pick], states[pick], false)
+	case pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16:
+		r, g, b := gm.pileColor(pick - EMPTY_PILE1)
+		gm.piles[pick-EMPTY_PILE1].SetColor(r, g, b, 1)
+	}
+}
+
 // -------------------------------------------------------------------------
 // runSelect: if game select is active, then collect 5 system digits and
 // start that game
 func (gm *game) runSelect(eng *vu.Engine, in *vu.Input, delta time.Duration) {
-	for press := range in.Pressed {
-		switch press {
-		case vu.K0, vu.K1, vu.K2, vu.K3, vu.K4, vu.K5, vu.K6, vu.K7, vu.K8, vu.K9,
-			vu.KP0, vu.KP1, vu.KP2, vu.KP3, vu.KP4, vu.KP5, vu.KP6, vu.KP7, vu.KP8, vu.KP9:
-			gm.seedSelect = append(gm.seedSelect, press)
+	if gm.save.seedFixed() {
+		gm.seedSelect = gm.seedSelect[:0]
+		gm.state = gm.state &^ SelectState // exit select state
+		return
+	}
+	intents, keys := selectIntents(in.Pressed)
+	for i, it := range intents {
+		switch it {
+		case digitIntent:
+			gm.seedSelect = append(gm.seedSelect, keys[i])
 			seedStr, seed := parseSelectKeys(gm.seedSelect)
 			gm.updateGameSeed(seedStr)
 
 			// finish game select when there are 6 digits.
 			if len(gm.seedSelect) == 6 {
-				gm.save.persistSeed(seed)
-				gm.resetBoard()
+				if gm.confirmNewGame(time.Now()) {
+					gm.save.persistSeed(seed)
+					gm.resetBoard()
+				} else {
+					gm.updateGameSeed(fmt.Sprintf("%06d", gm.save.Seed)) // stay in the current game.
+				}
 				gm.seedSelect = gm.seedSelect[:0]
 				gm.state = gm.state &^ SelectState // exit select state
 			}
-		default:
+		case cancelIntent:
 			// any non-numeric key exits select state
 			gm.seedSelect = gm.seedSelect[:0]
 			gm.state = gm.state &^ SelectState // exit select state
@@ -648,34 +2307,35 @@ func (gm *game) runSelect(eng *vu.Engine, in *vu.Input, delta time.Duration) {
 // runSpeedDial: if game speed dial is active, then churn the game seed
 // until the button is released.
 func (gm *game) runSpeedDial(eng *vu.Engine, in *vu.Input, delta time.Duration) {
+	if gm.save.seedFixed() {
+		gm.state = gm.state &^ DialState // exit dial state
+		return
+	}
 
 	// update user mouse moves.
 	ax, ay := math.Abs(float64(gm.dx)), math.Abs(float64(gm.dy))
 	gm.mx, gm.my = int(in.Mx), int(in.My)
 
-	// exit speed dial select if the button press is released.
-	_, ok1 := in.Down[vu.KML]
-	_, ok2 := in.Down[vu.TOUCH]
-	if !ok1 && !ok2 {
-		gm.save.persistSeed(uint(gm.seedDial))
-		gm.resetBoard()
-		gm.state = gm.state &^ DialState // exit dial state
-	}
-
-	// react to continuous press events.
-	for press, _ := range in.Down {
-		switch {
-		case press == vu.KML || press == vu.TOUCH:
+	// react to continuous press events, and exit speed dial select
+	// when the button press is released or any other key is pressed.
+	for _, it := range dialIntents(in) {
+		switch it {
+		case holdIntent:
 			switch {
 			case gm.overButton(gm.prevButton, gm.mx, gm.my):
 				gm.speedDial(ax, ay, -1)
+				gm.updateGameSeed(fmt.Sprintf("%06d", gm.seedDial))
 			case gm.overButton(gm.nextButton, gm.mx, gm.my):
 				gm.speedDial(ax, ay, 1)
+				gm.updateGameSeed(fmt.Sprintf("%06d", gm.seedDial))
+			}
+		case releaseIntent:
+			if gm.confirmNewGame(time.Now()) {
+				gm.save.persistSeed(uint(gm.seedDial))
+				gm.resetBoard()
+			} else {
+				gm.updateGameSeed(fmt.Sprintf("%06d", gm.save.Seed)) // stay in the current game.
 			}
-		default:
-			// exit if any other key is pressed.
-			gm.save.persistSeed(uint(gm.seedDial))
-			gm.resetBoard()
 			gm.state = gm.state &^ DialState // exit dial state
 		}
 	}
@@ -684,64 +2344,115 @@ func (gm *game) runSpeedDial(eng *vu.Engine, in *vu.Input, delta time.Duration)
 // speedDial handles rapidly incrementing or decrementing the game seed
 // while in DialState.
 // dir is 1 or -1 for increment and decrement
+// The seed is clamped to the valid games, staying in DialState so the
+// player can dial back. The seed is only committed on release.
 func (gm *game) speedDial(ax, ay float64, dir int) {
 	exp := 2.5
 	gm.seedDial = gm.seedDial + dir*int(math.Pow(ay, exp)) + dir*int(ax)
-	if gm.seedDial <= 0 {
-		gm.seedDial = 0
+	gm.seedDial = min(max(gm.seedDial, 0), int(MAX_SEED))
+}
+
+// -------------------------------------------------------------------------
+
+// cardFaceNames are the card face images in texture order. The first
+// 52 faces are in deck order so that card cid uses texture card<cid>.
+// The empty pile and foundation faces follow as textures card52 to card56.
+var cardFaceNames = []string{
+	"AC.png", "AD.png", "AH.png", "AS.png",
+	"2C.png", "2D.png", "2H.png", "2S.png",
+	"3C.png", "3D.png", "3H.png", "3S.png",
+	"4C.png", "4D.png", "4H.png", "4S.png",
+	"5C.png", "5D.png", "5H.png", "5S.png",
+	"6C.png", "6D.png", "6H.png", "6S.png",
+	"7C.png", "7D.png", "7H.png", "7S.png",
+	"8C.png", "8D.png", "8H.png", "8S.png",
+	"9C.png", "9D.png", "9H.png", "9S.png",
+	"TC.png", "TD.png", "TH.png", "TS.png",
+	"JC.png", "JD.png", "JH.png", "JS.png",
+	"QC.png", "QD.png", "QH.png", "QS.png",
+	"KC.png", "KD.png", "KH.png", "KS.png",
+
+	// empty card piles
+	"empty.png",
+
+	// empty foundation piles.
+	"FC.png", "FD.png", "FH.png", "FS.png",
+}
+
+// card texture sets. The labelled cards are a separate set so that the
+// card labels can be toggled without replacing textures that are in use.
+const (
+	plainCardTextures    = "card"
+	labelledCardTextures = "labelled"
+)
+
+// cardTextures returns the card texture set for the card labels preference.
+func cardTextures(labels bool) string {
+	if labels {
+		return labelledCardTextures
 	}
-	if gm.seedDial >= int(MAX_SEED) {
-		gm.seedDial = int(MAX_SEED)
+	return plainCardTextures
+}
+
+// addCard creates the card model using the current card textures.
+func (gm *game) addCard(cid uint) *vu.Entity {
+	tex := fmt.Sprintf("%s%d", cardTextures(gm.save.CardLabels), cid)
+	card := gm.scene.AddModel("shd:card", "msh:card", "tex:color:"+tex)
+	return card.SetScale(cardScale, cardScale, cardScale).SetColor(1, 1, 1, 1)
+}
+
+// toggleCardLabels switches between the plain and the labelled cards.
+// The labelled textures are created the first time they are needed,
+// and the cards are recreated to use the other textures.
+func (gm *game) toggleCardLabels() {
+	gm.save.CardLabels = !gm.save.CardLabels
+	gm.save.persist()
+	if gm.save.CardLabels && !gm.labelledCards {
+		gm.createCardAssets(labelledCardTextures, true)
+		gm.labelledCards = true
 	}
-	gm.updateGameSeed(fmt.Sprintf("%06d", gm.seedDial))
-	if gm.seedDial == 0 || gm.seedDial == int(MAX_SEED) {
-		gm.save.persistSeed(uint(gm.seedDial))
-		gm.resetBoard()
-		gm.state = gm.state &^ DialState // exit dial state
+	for cid := AC; cid <= KS; cid++ {
+		gm.cards[cid].Dispose(gm.eng)
+		gm.cards[cid] = gm.addCard(cid)
 	}
+	gm.redraw = true
+	gm.redrawBoard()
 }
 
-// -------------------------------------------------------------------------
-
 // createCardAssets by merging each card face with a common card back.
-func (gm *game) createCardAssets() {
+// The textures are named for the texture set, ie: card0 to card56.
+func (gm *game) createCardAssets(set string, labelled bool) {
 
 	// load the UV template for all cards.
 	uvImg := getNRGBA("cardBase.png")
 
-	// card front images are imported as image data and used to
-	// create individual card UV textures.
-	cardFaceNames := []string{
-		"AC.png", "AD.png", "AH.png", "AS.png",
-		"2C.png", "2D.png", "2H.png", "2S.png",
-		"3C.png", "3D.png", "3H.png", "3S.png",
-		"4C.png", "4D.png", "4H.png", "4S.png",
-		"5C.png", "5D.png", "5H.png", "5S.png",
-		"6C.png", "6D.png", "6H.png", "6S.png",
-		"7C.png", "7D.png", "7H.png", "7S.png",
-		"8C.png", "8D.png", "8H.png", "8S.png",
-		"9C.png", "9D.png", "9H.png", "9S.png",
-		"TC.png", "TD.png", "TH.png", "TS.png",
-		"JC.png", "JD.png", "JH.png", "JS.png",
-		"QC.png", "QD.png", "QH.png", "QS.png",
-		"KC.png", "KD.png", "KH.png", "KS.png",
-
-		// empty card piles
-		"empty.png",
-
-		// empty foundation piles.
-		"FC.png", "FD.png", "FH.png", "FS.png",
+	// player card faces in the data directory replace the embedded faces.
+	faceDir := path.Join(path.Dir(gm.save.file), cardFacesDir)
+
+	// optional large card labels.
+	var labels *load.FontAtlas
+	if labelled {
+		var err error
+		if labels, err = load.TTFont("144:hack.ttf"); err != nil {
+			slog.Warn("card labels disabled", "err", err)
+		}
 	}
 
 	// create card assets by combining the UV template with the card faces.
 	cardAssets := []*load.ImageData{}
 	copyPoint := image.Point{1, 174}
-	for _, faceName := range cardFaceNames {
+	for i, faceName := range cardFaceNames {
 
 		// create new card UV image for each face.
 		base := image.NewNRGBA(uvImg.Bounds())
 		draw.Draw(base, uvImg.Bounds(), uvImg, image.ZP, draw.Src)
 		faceImg := getNRGBA(faceName) // load the card face image.
+		if custom := customFace(faceDir, faceName, faceImg.Bounds().Size()); custom != nil {
+			faceImg = custom // use the player card face.
+		}
+		if labels != nil && i < len(deck) {
+			faceImg = labelCardFace(faceImg, labels, deck[i])
+		}
 
 		// combine the two into the final card UV texture.
 		copyRect := image.Rectangle{copyPoint, copyPoint.Add(faceImg.Bounds().Size())}
@@ -757,46 +2468,72 @@ func (gm *game) createCardAssets() {
 	}
 
 	// upload all the card uv images into texture assets.
-	gm.eng.MakeTextures("card", cardAssets)
+	gm.eng.MakeTextures(set, cardAssets)
+}
+
+// screener converts world coordinates to screen pixel coordinates.
+// Implemented by vu.Camera.
+type screener interface {
+	Screen(wx, wy, wz float64, ww, wh int) (sx, sy int)
+}
+
+// cardTilt returns the card rotation in radians around the X axis
+// for the given board location. Only cascade cards are tilted.
+func cardTilt(boardID uint, tilted bool) float64 {
+	if tilted && boardID >= 8 && boardID <= MAX_BOARD_ID {
+		return lin.Rad(cardTiltAngle)
+	}
+	return 0
+}
+
+// cardCorners returns the world coordinates of the top left and bottom
+// right corners of a card centered at x,y,z and tilted around the X axis.
+func cardCorners(x, y, z, tilt float64) (tl, br lin.V3) {
+	hx, hy := halfCardWidth*cardScale, halfCardHeight*cardScale
+	dy, dz := hy*math.Cos(tilt), hy*math.Sin(tilt)
+	tl = lin.V3{X: x - hx, Y: y + dy, Z: z + dz}
+	br = lin.V3{X: x + hx, Y: y - dy, Z: z - dz}
+	return tl, br
 }
 
 // hitCard takes advantage that all the cards are facing the player
 // along the Z axis. Converting the card corner world coordinates
 // into screen coordinates gives a simple check with the mouse.
-// The closer card is the picked card.
-func (gm *game) hitCard(cam *vu.Camera, ww, wh, mx, my int) (cid uint) {
-	// card corner offsets in world coordinates.
-	hx, hy := halfCardWidth*cardScale, halfCardHeight*cardScale
-	hitCard, hitZ := HIDDEN_CARD, -100.0 // no card hit
+// Tilted cards project their tilted corners.
+// The closer card is the picked card. Returns NO_HIT if the
+// mouse is not over a card or an empty pile.
+func hitCard(cam screener, board [52]uint, tilted bool, ww, wh, mx, my int) (cid uint) {
+	hitCard, hitZ := NO_HIT, -100.0 // no card hit
 
 	// check the empty piles.
 	for pid := uint(0); pid < 16; pid++ {
-		wx, wy, wz := gm.piles[pid].At()
+		wx, wy, wz := placePile(pid)
 
 		// get the corner pixel coordinates.
-		xtop, ytop := cam.Screen(wx-hx, wy+hy, wz, ww, wh)
-		xbot, ybot := cam.Screen(wx+hx, wy-hy, wz, ww, wh)
+		tl, br := cardCorners(wx, wy, wz, 0)
+		xtop, ytop := cam.Screen(tl.X, tl.Y, tl.Z, ww, wh)
+		xbot, ybot := cam.Screen(br.X, br.Y, br.Z, ww, wh)
 		if mx < xtop || mx > xbot || my < ytop || my > ybot {
 			continue // did not hit this card.
 		}
 
 		// card hit, pick the card if it is closer.
 		if wz > hitZ {
-			hitCard, hitZ = pid+100, wz
+			hitCard, hitZ = pid+EMPTY_PILE1, wz
 		}
 	}
 
 	// test the visible cards
-	board := gm.logic.Board()
 	for cid := AC; cid <= KS; cid++ {
 		if board[cid] >= HIDDEN_CARD {
 			continue // can't interact with hidden cards.
 		}
-		wx, wy, wz := gm.cards[cid].At()
+		wx, wy, wz := placeCard(board[cid])
 
 		// get the corner pixel coordinates.
-		xtop, ytop := cam.Screen(wx-hx, wy+hy, wz, ww, wh)
-		xbot, ybot := cam.Screen(wx+hx, wy-hy, wz, ww, wh)
+		tl, br := cardCorners(wx, wy, wz, cardTilt(board[cid], tilted))
+		xtop, ytop := cam.Screen(tl.X, tl.Y, tl.Z, ww, wh)
+		xbot, ybot := cam.Screen(br.X, br.Y, br.Z, ww, wh)
 		if mx < xtop || mx > xbot || my < ytop || my > ybot {
 			continue // did not hit this card.
 		}
@@ -825,6 +2562,65 @@ func getNRGBA(name string) *image.NRGBA {
 	return image.NewNRGBA(image.Rect(0, 0, 0, 0))
 }
 
+// labelCardFace returns a copy of the card face with the card symbol,
+// ie: "QH", drawn in large text in the top left corner so that the card
+// can be read on small screens. Red suits are labelled in red.
+func labelCardFace(face *image.NRGBA, atlas *load.FontAtlas, c Card) *image.NRGBA {
+	labelled := image.NewNRGBA(face.Bounds())
+	draw.Draw(labelled, labelled.Bounds(), face, face.Bounds().Min, draw.Src)
+	ink := image.NewUniform(color.NRGBA{0, 0, 0, 255})
+	if c.Color == RED {
+		ink = image.NewUniform(color.NRGBA{200, 0, 0, 255})
+	}
+	glyphs := map[rune]load.Glyph{}
+	for _, g := range atlas.Glyphs {
+		glyphs[g.Char] = g
+	}
+	pen := image.Point{16, 8} // inset from the card corner.
+	for _, r := range c.Sym {
+		g, ok := glyphs[r]
+		if !ok {
+			continue // font is missing the character.
+		}
+		src := image.Rect(g.X, g.Y, g.X+g.W, g.Y+g.H)
+		at := pen.Add(image.Point{g.Xo, g.Yo})
+		draw.DrawMask(labelled, image.Rectangle{at, at.Add(src.Size())}, ink, image.Point{}, atlas.NRGBA, src.Min, draw.Over)
+		pen.X += g.Xa
+	}
+	return labelled
+}
+
+// cardFacesDir is the data directory folder for player card faces.
+const cardFacesDir = "cards"
+
+// customFace returns the named player card face image from the given
+// directory. Returns nil if there is no player card face, or if the face
+// can't be used because it is not the same size as the embedded face.
+func customFace(dir, name string, size image.Point) *image.NRGBA {
+	data, err := os.ReadFile(path.Join(dir, name))
+	if err != nil {
+		return nil // no player card face.
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		slog.Warn("ignoring card face", "name", name, "err", err)
+		return nil
+	}
+	if img.Bounds().Size() != size {
+		slog.Warn("ignoring card face", "name", name, "size", img.Bounds().Size(), "expected", size)
+		return nil
+	}
+	face := image.NewNRGBA(image.Rectangle{Max: size})
+	draw.Draw(face, face.Bounds(), img, img.Bounds().Min, draw.Src)
+	return face
+}
+
+// numberpad returns true if the player can type digits to edit
+// the game seed.
+func (gm *game) numberpad() bool {
+	return numberpadExists(gm.save.Numberpad, platformNumberpad, gm.digitTyped)
+}
+
 // parseSelectKeys turns a slice of numeric key presses into a number
 // and a display string. Expects only digit keys.
 func parseSelectKeys(keys []int32) (display string, number uint) {
@@ -876,6 +2672,68 @@ func gameColor(seed uint) (r, g, b float64) {
 	return r, g, b
 }
 
+// highlightRGB returns the selection color for the given HighlightColor
+// preset or custom "#rrggbb" color. Unknown colors are gold.
+func highlightRGB(color string) (rgb [3]float64) {
+	switch color {
+	case highlightCyan:
+		return [3]float64{0.0, 0.9, 1.0}
+	case highlightMagenta:
+		return [3]float64{1.0, 0.3, 1.0}
+	case highlightWhite:
+		return [3]float64{1.0, 1.0, 1.0}
+	}
+	var r, g, b uint8
+	if n, err := fmt.Sscanf(color, "#%02x%02x%02x", &r, &g, &b); err == nil && n == 3 && len(color) == 7 {
+		return [3]float64{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+	}
+	return [3]float64{1.0, 0.8, 0.0} // gold
+}
+
+// highContrastBoard is the fixed dark board color for the high
+// contrast theme, replacing the seed color.
+var highContrastBoard = [3]float64{0.05, 0.05, 0.08}
+
+// boardColor returns the board color for the given seed, or the
+// fixed high contrast board color.
+func boardColor(seed uint, highContrast bool) (r, g, b float64) {
+	if highContrast {
+		return highContrastBoard[0], highContrastBoard[1], highContrastBoard[2]
+	}
+	return gameColor(seed)
+}
+
+// hoverColor returns the drop highlight color: green if the selected
+// cards can be placed, red if not. The colors are stronger for the
+// high contrast theme.
+func hoverColor(valid, highContrast bool) (r, g, b float64) {
+	weak := 0.4
+	if highContrast {
+		weak = 0.1
+	}
+	if valid {
+		return weak, 1.0, weak
+	}
+	return 1.0, weak, weak
+}
+
+// suitColors are the four color deck suit colors:
+// green clubs, blue diamonds, red hearts, and black spades.
+var suitColors = [4][3]float64{
+	{0.0, 0.6, 0.0}, // CLB
+	{0.0, 0.4, 0.9}, // DMD
+	{0.9, 0.0, 0.0}, // HRT
+	{0.0, 0.0, 0.0}, // SPD
+}
+
+// foundationTint returns a subtle suit color for the given
+// foundation pile FC:FS. The suit color is mostly faded to white.
+func foundationTint(pileID uint) (r, g, b float64) {
+	c := suitColors[pileID-FC]
+	fade := 0.7
+	return lin.Lerp(c[0], 1, fade), lin.Lerp(c[1], 1, fade), lin.Lerp(c[2], 1, fade)
+}
+
 // HSLtoRGB converts color space values.
 // h is 0 to 360, S, L are percentages.
 func HSLtoRGB(h, s, l float64) (r, g, b float64) {
@@ -0,0 +1,1240 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gazed/vu"
+	"github.com/gazed/vu/load"
+	"github.com/gazed/vu/math/lin"
+)
+
+// go test -run Density
+func TestTextDensity(t *testing.T) {
+	tests := []struct {
+		textSize float64 // on screen text size in pixels.
+		density  int     // expected pixel density.
+		w, h     int     // expected text texture size.
+		font     string  // expected font asset.
+	}{
+		{0, 1, 192, 192, "hack48"},
+		{150, 1, 192, 192, "hack48"},
+		{192, 1, 192, 192, "hack48"},
+		{288, 2, 384, 384, "hack96"},
+		{500, 3, 576, 576, "hack144"},
+		{2000, 3, 576, 576, "hack144"}, // capped at max density.
+	}
+	for _, tt := range tests {
+		density := textDensity(tt.textSize)
+		if density != tt.density {
+			t.Fatalf("size %f expected density %d got %d", tt.textSize, tt.density, density)
+		}
+		if w, h := textTextureSize(density); w != tt.w || h != tt.h {
+			t.Errorf("density %d expected %dx%d got %dx%d", density, tt.w, tt.h, w, h)
+		}
+		if font := textFont(density); font != tt.font {
+			t.Errorf("density %d expected font %s got %s", density, tt.font, font)
+		}
+	}
+}
+
+// orthoCam is a simple screener for testing: 100 pixels per meter
+// with the screen origin at world -4,1.
+type orthoCam struct{}
+
+func (orthoCam) Screen(wx, wy, wz float64, ww, wh int) (sx, sy int) {
+	return int((wx + 4) * 100), int((1 - wy) * 100)
+}
+
+// go test -run HitCard
+func TestHitCard(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	board := l.Board()
+	cam := orthoCam{}
+
+	// clicks that miss everything.
+	for _, click := range [][2]int{{5000, 5000}, {-10, -10}, {0, 0}} {
+		if pick := hitCard(cam, board, false, 800, 1000, click[0], click[1]); pick != NO_HIT {
+			t.Errorf("click %v expected no hit got %d", click, pick)
+		}
+	}
+
+	// click an empty freecell and the first cascade card.
+	x, y, _ := placePile(0)
+	mx, my := cam.Screen(x, y, 0, 800, 1000)
+	if pick := hitCard(cam, board, false, 800, 1000, mx, my); pick != EMPTY_PILE1 {
+		t.Errorf("expected empty freecell got %d", pick)
+	}
+	x, y, _ = placeCard(8)
+	mx, my = cam.Screen(x, y+0.3, 0, 800, 1000) // above the overlapping card.
+	if pick := hitCard(cam, board, false, 800, 1000, mx, my); pick != l.cardAt(8) {
+		t.Errorf("expected card %d got %d", l.cardAt(8), pick)
+	}
+}
+
+// go test -run Tilt
+func TestHitTiltedCard(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	board := l.Board()
+	cam := orthoCam{}
+
+	// flat and top row cards are not tilted.
+	if cardTilt(59, false) != 0 || cardTilt(FC, true) != 0 {
+		t.Errorf("expected no tilt")
+	}
+	tilt := cardTilt(59, true)
+	if tilt <= 0 {
+		t.Fatalf("expected cascade tilt")
+	}
+
+	// tilted corners are closer to the card center vertically
+	// and the top leans towards the player.
+	flatTL, flatBR := cardCorners(0, 0, 0, 0)
+	tiltTL, tiltBR := cardCorners(0, 0, 0, tilt)
+	if flatTL.Z != 0 || flatBR.Z != 0 || flatTL.Y != -flatBR.Y {
+		t.Errorf("unexpected flat corners %v %v", flatTL, flatBR)
+	}
+	if tiltTL.Y >= flatTL.Y || tiltBR.Y <= flatBR.Y || tiltTL.Z <= 0 || tiltBR.Z >= 0 {
+		t.Errorf("unexpected tilted corners %v %v", tiltTL, tiltBR)
+	}
+	if tiltTL.X != flatTL.X || tiltBR.X != flatBR.X {
+		t.Errorf("tilt should not change the card width")
+	}
+
+	// a click just inside the bottom edge of the last card in a cascade
+	// hits the flat card but misses the shorter projected tilted card.
+	x, y, _ := placeCard(59)
+	mx, my := cam.Screen(x, y+flatBR.Y*0.995, 0, 800, 1000)
+	if pick := hitCard(cam, board, false, 800, 1000, mx, my); pick != l.cardAt(59) {
+		t.Errorf("expected flat card %d got %d", l.cardAt(59), pick)
+	}
+	if pick := hitCard(cam, board, true, 800, 1000, mx, my); pick != NO_HIT {
+		t.Errorf("expected tilted card miss got %d", pick)
+	}
+}
+
+// go test -run Layout
+func TestButtonLayout(t *testing.T) {
+	if size, _ := buttonLayout(1200, 1); size != 160 {
+		t.Errorf("expected full size buttons got %f", size)
+	}
+	for _, fw := range []float64{720, 600, minWindowWidth} {
+		size, gap := buttonLayout(fw, 1)
+		if 4*size+2*gap > fw {
+			t.Errorf("width %f: buttons overlap with size %f", fw, size)
+		}
+	}
+	for _, fw := range []float64{200, 50, 0} {
+		if size, _ := buttonLayout(fw, 1); size != minButtonSize {
+			t.Errorf("width %f: expected minimum button size got %f", fw, size)
+		}
+	}
+}
+
+// go test -run ButtonScale
+func TestButtonScale(t *testing.T) {
+	for _, scale := range []float64{0, 0.5, 0.75, 1, 1.5, 2, 3} {
+		for _, fw := range []float64{1200, 720, minWindowWidth} {
+			size, gap := buttonLayout(fw, scale)
+			if 4*size+2*gap > fw && size > minButtonSize {
+				t.Errorf("scale %f width %f: buttons overlap with size %f", scale, fw, size)
+			}
+
+			// hits are checked at the scaled button sizes.
+			undo, prev, next, seed := buttonXs(0, fw, size, gap, false)
+			y := 1000 - size
+			buttons := []struct{ x, w float64 }{{undo, size}, {prev, size * 0.5}, {next, size * 0.5}, {seed, size * 2}}
+			for i, b := range buttons {
+				left, right := int(b.x-b.w*0.5), int(b.x+b.w*0.5)
+				if !inButton(left+1, int(y), b.x, y, b.w, size) || !inButton(right-1, int(y), b.x, y, b.w, size) {
+					t.Errorf("scale %f width %f: expected button %d hit inside its edges", scale, fw, i)
+				}
+				if inButton(left-1, int(y), b.x, y, b.w, size) || inButton(right+1, int(y), b.x, y, b.w, size) {
+					t.Errorf("scale %f width %f: expected button %d miss outside its edges", scale, fw, i)
+				}
+				for j, other := range buttons {
+					if j != i && inButton(left+1, int(y), other.x, y, other.w, size) {
+						t.Errorf("scale %f width %f: button %d overlaps button %d", scale, fw, i, j)
+					}
+				}
+			}
+		}
+	}
+	small, _ := buttonLayout(1200, 0.1)
+	large, _ := buttonLayout(1200, 10)
+	if small != 160*minButtonScale || large != (1200-2*40*maxButtonScale)*0.25 {
+		t.Errorf("expected clamped scales got sizes %f %f", small, large)
+	}
+}
+
+// go test -run LeftHanded
+func TestLeftHanded(t *testing.T) {
+	size, gap := buttonLayout(1200, 1)
+	undo, prev, next, seed := buttonXs(0, 1200, size, gap, false)
+	if undo != 0.5*size+gap || next != 1200-0.25*size-gap {
+		t.Errorf("expected undo on the left and next on the right got %f %f", undo, next)
+	}
+	lundo, lprev, lnext, lseed := buttonXs(0, 1200, size, gap, true)
+	for _, tt := range []struct{ right, left float64 }{{undo, lundo}, {prev, lprev}, {next, lnext}, {seed, lseed}} {
+		if tt.left != 1200-tt.right {
+			t.Errorf("expected %f mirrored to %f got %f", tt.right, 1200-tt.right, tt.left)
+		}
+	}
+	if lundo <= lnext || lprev <= lnext {
+		t.Errorf("expected undo on the right and next on the left got %f %f", lundo, lnext)
+	}
+}
+
+// go test -run SpeedDial
+func TestSpeedDial(t *testing.T) {
+	gm := &game{save: &Save{Seed: 999_990}, state: DialState, seedDial: 999_990}
+	gm.speedDial(40, 10, 1) // a fast flick past the last game.
+	if gm.seedDial != int(MAX_SEED) || gm.state != DialState || gm.save.Seed != 999_990 {
+		t.Errorf("expected clamp to %d while dialing got %d state %d seed %d", MAX_SEED, gm.seedDial, gm.state, gm.save.Seed)
+	}
+	gm.speedDial(5, 0, -1) // dial back.
+	if gm.seedDial != int(MAX_SEED)-5 || gm.state != DialState {
+		t.Errorf("expected to dial back to %d got %d", MAX_SEED-5, gm.seedDial)
+	}
+	gm.seedDial = 3
+	gm.speedDial(40, 10, -1)
+	if gm.seedDial != 0 || gm.state != DialState {
+		t.Errorf("expected clamp to 0 while dialing got %d state %d", gm.seedDial, gm.state)
+	}
+}
+
+// go test -run SeedLock
+func TestSeedLock(t *testing.T) {
+	gm := &game{save: newSave(t.TempDir(), "freecell.save"), logic: &logic{}}
+	gm.save.Seed, gm.save.SeedLock = 42, true
+	gm.save.Attempts[7] = 3 // a game to practice.
+	gm.nextGame()
+	gm.prevGame()
+	gm.handleButtonHold(0, 0, time.Minute)
+	gm.dispatch(nil, practiceIntent)
+	gm.dispatch(nil, hardGameIntent)
+	if _, pending := gm.seeds.commit(time.Now().Add(time.Hour)); pending || gm.state != PlayState {
+		t.Errorf("expected no seed changes got pending %t state %d", pending, gm.state)
+	}
+
+	// selecting or dialing a seed exits without changing the seed.
+	digits := &vu.Input{Pressed: map[int32]bool{vu.K1: true}}
+	gm.state, gm.seedSelect = SelectState, []int32{vu.K1, vu.K2, vu.K3, vu.K4, vu.K5}
+	gm.runSelect(nil, digits, 0)
+	gm.state, gm.seedDial = DialState, 99
+	gm.runSpeedDial(nil, &vu.Input{}, 0)
+	if gm.save.Seed != 42 || gm.state != PlayState || len(gm.seedSelect) != 0 {
+		t.Errorf("expected seed 42 in play got seed %d state %d", gm.save.Seed, gm.state)
+	}
+}
+
+// go test -run RestoreWonGame
+func TestRestoreWonGame(t *testing.T) {
+	gm := &game{save: newSave(t.TempDir(), "freecell.save"), logic: &logic{}}
+	gm.save.Seed = 1
+	gm.logic.NewGame(1)
+	for _, mv := range seed1Script[:2] {
+		gm.logic.Interact(mv[0])
+		gm.logic.Interact(mv[1])
+		gm.logic.AutoMoveAll()
+	}
+	gm.save.persistBookmark(bookmarkName(1, gm.logic.MoveNumber()), gm.logic.Bookmark())
+	for _, mv := range seed1Script[2:] {
+		gm.logic.Interact(mv[0])
+		gm.logic.Interact(mv[1])
+		gm.logic.AutoMoveAll()
+	}
+
+	// a won game can't be restored, and so can't be won and scored again.
+	gm.gameOver = gm.logic.IsGameWon()
+	gm.dispatch(nil, restoreIntent)
+	if !gm.gameOver || !gm.logic.IsGameWon() {
+		t.Errorf("expected the won game to stay over got move %d", gm.logic.MoveNumber())
+	}
+}
+
+// go test -run RepeatUntilWon
+func TestRepeatUntilWon(t *testing.T) {
+	gm := &game{save: newSave(t.TempDir(), "freecell.save"), logic: &logic{}}
+	gm.save.Seed, gm.save.RepeatUntilWon = 42, true
+	gm.save.Attempts[7] = 3 // a game to practice.
+	gm.logic.NewGame(42)
+
+	// other games can't be chosen.
+	gm.dispatch(nil, practiceIntent)
+	gm.dispatch(nil, hardGameIntent)
+	gm.handleButtonHold(0, 0, time.Minute)
+	if gm.save.Seed != 42 || gm.state != PlayState {
+		t.Errorf("expected seed 42 in play got seed %d state %d", gm.save.Seed, gm.state)
+	}
+
+	// a restart deals the same game again.
+	for _, restart := range []func(){gm.nextGame, gm.prevGame} {
+		restart()
+		if seed, ok := gm.seeds.commit(time.Now().Add(time.Hour)); !ok || seed != 42 {
+			t.Errorf("expected seed 42 to be dealt again got %d %t", seed, ok)
+		}
+	}
+
+	// each abandoned attempt is counted when the game is dealt again,
+	// ranked or not, until the game is won.
+	if gm.save.attempt(42) != 1 || attemptText(gm.save.attempt(42)) != "attempt 1" {
+		t.Errorf("expected the first attempt got %d", gm.save.attempt(42))
+	}
+	gm.dealGame() // an unplayed deal is not an attempt.
+	for _, unranked := range []bool{false, true} {
+		played := gm.logic.board
+		played[AC], played[KS] = played[KS], played[AC]
+		gm.logic.moves.record(played) // a move was played.
+		gm.unranked = unranked
+		gm.dealGame()
+		if gm.logic.gameSeed != 42 || gm.logic.MoveNumber() != 0 {
+			t.Errorf("expected seed 42 dealt again got %d", gm.logic.gameSeed)
+		}
+	}
+	if gm.save.attempt(42) != 3 {
+		t.Errorf("expected the third attempt got %d", gm.save.attempt(42))
+	}
+
+	// a stuck game is dealt again after the player is told,
+	// and not while an undo is animating.
+	now := time.Now()
+	stuck := now.Add(toastDuration)
+	if stuckRedealDue(stuck, now, false) || !stuckRedealDue(stuck, stuck, false) || stuckRedealDue(stuck, stuck, true) {
+		t.Errorf("expected the redeal once the toast expires")
+	}
+	if stuckRedealDue(time.Time{}, stuck, false) {
+		t.Errorf("expected no redeal unless stuck")
+	}
+	gm.save.recordAttemptsToWin(42)
+	gm.save.scores()[42] = 95
+	if gm.save.AttemptsToWin[42] != 3 || gm.save.RepeatUntilWon {
+		t.Errorf("expected a first win in 3 attempts to end repeating got %d %t", gm.save.AttemptsToWin[42], gm.save.RepeatUntilWon)
+	}
+
+	// later wins keep the attempts until the first win.
+	gm.save.recordLoss(42)
+	gm.save.recordAttemptsToWin(42)
+	if gm.save.AttemptsToWin[42] != 3 {
+		t.Errorf("expected 3 attempts to win got %d", gm.save.AttemptsToWin[42])
+	}
+}
+
+// go test -run CustomGame
+func TestCustomGame(t *testing.T) {
+	dir := t.TempDir()
+	gm := &game{save: newSave(dir, "freecell.save"), logic: &logic{}}
+	gm.save.Seed = 1
+	gm.logic.NewGame(1)
+	if _, _, err := gm.save.importLayout(); err == nil {
+		t.Errorf("expected no layout to import")
+	}
+	custom := slices.Clone(games[1])
+	custom[0], custom[1] = custom[1], custom[0]
+	if err := os.WriteFile(filepath.Join(dir, layoutImportFile), []byte(strings.Join(custom, " ")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, layout, err := gm.save.importLayout()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the layout is played as an unranked game without a seed...
+	gm.layout = layout
+	if _, warning := gm.dealGame(); !gm.logic.custom || !gm.unranked || warning == "" || gm.layout != "" {
+		t.Fatalf("expected an unranked custom game got %t %t %q", gm.logic.custom, gm.unranked, warning)
+	}
+	if gm.logic.deal[0].Sym != custom[0] || gm.save.Seed != 1 {
+		t.Errorf("expected the custom deal to keep seed 1")
+	}
+
+	// ... that isn't scored when it is left.
+	gm.save.RepeatUntilWon = true
+	played := gm.logic.board
+	played[AC], played[KS] = played[KS], played[AC]
+	gm.logic.moves.record(played)
+	if gm.dealGame(); gm.logic.custom || gm.logic.gameSeed != 1 || len(gm.save.Attempts) != 0 {
+		t.Errorf("expected seed 1 dealt without attempts got %d %v", gm.logic.gameSeed, gm.save.Attempts)
+	}
+}
+
+// go test -run FoundationPick
+func TestFoundationPick(t *testing.T) {
+	var won [52]uint // kings on top of the foundations.
+	for cid := AC; cid <= KS; cid++ {
+		won[cid] = FC + getCard(cid).Suit + HIDDEN_CARD
+		if getCard(cid).Rank == KING {
+			won[cid] = FC + getCard(cid).Suit
+		}
+	}
+	for _, pick := range []uint{EMPTY_PILE1 + FC, EMPTY_PILE1 + FS, KC, KS} {
+		if !isFoundationPick(won, pick) {
+			t.Errorf("expected %d to be a foundation pick", pick)
+		}
+	}
+	l := &logic{}
+	l.NewGame(1)
+	if isFoundationPick(won, AC) {
+		t.Errorf("expected buried foundation cards not to be picked")
+	}
+	for _, pick := range []uint{EMPTY_PILE1, EMPTY_PILE1 + 8, AC, KS, NO_HIT} {
+		if isFoundationPick(l.Board(), pick) {
+			t.Errorf("expected %d not to be a foundation pick", pick)
+		}
+	}
+}
+
+// go test -run BoardStates
+func TestBoardStates(t *testing.T) {
+	gm := &game{save: newSave(t.TempDir(), "freecell.save"), logic: &logic{}}
+	gm.logic.NewGame(1)
+	top := uint(0) // last card dealt to the first cascade.
+	for cid, bid := range gm.logic.Board() {
+		if bid%8 == 0 && bid > gm.logic.Board()[top] {
+			top = uint(cid)
+		}
+	}
+	plain := gm.boardStates()
+	gm.logic.Interact(top)
+	selected := gm.boardStates()
+	if s := selected[top]; s.r != 1.0 || s.g != 0.8 || s.b != 0.0 || s.x != plain[top].x || s.y != plain[top].y {
+		t.Errorf("expected the selected card to be highlighted in place got %+v", s)
+	}
+	gm.logic.Interact(top) // deselect.
+	gm.showBlockers = true
+	blocked := gm.boardStates()
+	aces, blockers := gm.logic.AceBlockers()
+	if len(aces) == 0 || blocked[aces[0]] == plain[aces[0]] || blocked[blockers[0]] == plain[blockers[0]] {
+		t.Errorf("expected the aces and blockers to be highlighted")
+	}
+	if blocked[aces[0]].x != plain[aces[0]].x || blocked[aces[0]].y != plain[aces[0]].y {
+		t.Errorf("expected highlighted cards to stay in place")
+	}
+	gm.showBlockers = false
+
+	// the empty piles keep their colors after a hover.
+	fr, fg, fb := foundationTint(FH)
+	if r, g, b := gm.pileColor(FH); r != fr || g != fg || b != fb {
+		t.Errorf("expected the foundation tint got %f %f %f", r, g, b)
+	}
+	gm.logic.closedCells = 1
+	if r, g, b := gm.pileColor(3); r != 0.3 || g != 0.3 || b != 0.3 {
+		t.Errorf("expected a closed free cell got %f %f %f", r, g, b)
+	}
+	if r, g, b := gm.pileColor(8); r != 1 || g != 1 || b != 1 {
+		t.Errorf("expected a plain cascade pile got %f %f %f", r, g, b)
+	}
+
+	// the tutorial hint survives a redraw of the hinted card.
+	gm.logic.NewGame(tutorialSeed)
+	gm.tutor = newTutorial()
+	hint := gm.tutor.expected(gm.logic)
+	if s := gm.boardStates()[hint]; s.r != 0.6 || s.g != 0.8 || s.b != 1.0 {
+		t.Errorf("expected the tutorial hint got %+v", s)
+	}
+}
+
+// go test -run AutoCompleteWins
+func TestAutoCompleteWins(t *testing.T) {
+	for _, autoPlay := range []string{autoPlayOnMove, autoPlayOnDemand, autoPlayOff} {
+		gm := &game{save: newSave(t.TempDir(), "freecell.save"), logic: &logic{}}
+		gm.save.AutoPlay = autoPlay
+		gm.logic.NewGame(1)
+		gm.logic.board = orderedBoard()
+		gm.logic.moves.record(gm.logic.board)
+		want := autoPlay != autoPlayOff
+		if got := gm.autoComplete(); got != want || gm.logic.IsGameWon() != want {
+			t.Errorf("%s: expected won %t got %t", autoPlay, want, gm.logic.IsGameWon())
+		}
+	}
+}
+
+// go test -run PlayTime
+func TestPlayTime(t *testing.T) {
+	dir := t.TempDir()
+	gm := &game{save: newSave(dir, "freecell.save")}
+	steps := []struct {
+		state int
+		delta time.Duration
+		saved bool
+	}{
+		{PlayState, 20 * time.Second, false},
+		{SelectState, 5 * time.Minute, false}, // selecting games is not play.
+		{PlayState, 30 * time.Second, false},
+		{DialState, 5 * time.Minute, false},
+		{PlayState, 15 * time.Second, true}, // a minute of play is saved.
+		{PlayState, 10 * time.Second, false},
+	}
+	for i, step := range steps {
+		gm.state = step.state
+		if saved := gm.trackPlayTime(step.delta); saved != step.saved {
+			t.Errorf("step %d: expected saved %t", i, step.saved)
+		}
+	}
+	if gm.save.TotalPlayTime != 75*time.Second {
+		t.Errorf("expected 1m15s of play got %s", gm.save.TotalPlayTime)
+	}
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	if restored.TotalPlayTime != 65*time.Second {
+		t.Errorf("expected 1m05s saved got %s", restored.TotalPlayTime)
+	}
+}
+
+// go test -run DealAnimation
+func TestDealAnimation(t *testing.T) {
+	gm := &game{logic: &logic{}, save: &Save{}}
+	gm.logic.NewGame(1)
+	previous := gm.logic.Board()
+	gm.logic.NewGame(2)
+
+	// the fast path does not animate and the board is the new deal.
+	if anim := gm.dealAnimation(previous, 100*time.Millisecond); anim != nil {
+		t.Errorf("expected no animation when dealing rapidly")
+	}
+	for i, c := range shuffle(2, deck) {
+		if got := gm.logic.Board()[c.ID]; got != uint(i)+8 {
+			t.Errorf("expected %s at %d got %d", c.Sym, i+8, got)
+		}
+	}
+	if anim := gm.dealAnimation(gm.logic.Board(), time.Second); anim != nil {
+		t.Errorf("expected no animation for an unchanged board")
+	}
+	gm.save.ReduceMotion = true
+	if anim := gm.dealAnimation(previous, time.Second); anim != nil {
+		t.Errorf("expected no animation when motion is reduced")
+	}
+
+	// a regular new game animates the deal.
+	gm.save.ReduceMotion = false
+	if anim := gm.dealAnimation(previous, time.Second); anim == nil {
+		t.Errorf("expected a deal animation")
+	}
+	gm.save.DealCards = true
+	if anim, ok := gm.dealAnimation(previous, time.Second).(*animation); !ok || anim.duration != 1200*time.Millisecond {
+		t.Errorf("expected the card by card deal animation")
+	}
+}
+
+// go test -run AutoPlay
+func TestAutoPlay(t *testing.T) {
+	for _, mode := range []string{autoPlayOnMove, autoPlayOff, autoPlayOnDemand, ""} {
+		gm := &game{logic: &logic{}, save: &Save{AutoPlay: mode}}
+		gm.logic.NewGame(1)
+		for _, mv := range seed1Script[:2] { // 2C to a freecell frees 3 cards.
+			gm.logic.Interact(mv[0])
+			gm.logic.Interact(mv[1])
+		}
+		board := gm.logic.Board()
+		afterMove, onDemand := 0, 0
+		for gm.autoMoveAfterMove() {
+			afterMove++
+		}
+		onDemand = gm.autoMoveOnDemand()
+		switch mode {
+		case autoPlayOff:
+			if afterMove != 0 || onDemand != 0 || gm.logic.Board() != board {
+				t.Errorf("%s: expected no auto moves got %d %d", mode, afterMove, onDemand)
+			}
+		case autoPlayOnDemand:
+			if afterMove != 0 || onDemand != 3 {
+				t.Errorf("%s: expected 0 3 auto moves got %d %d", mode, afterMove, onDemand)
+			}
+		default: // on-move, and unknown values.
+			if afterMove != 3 || onDemand != 0 {
+				t.Errorf("%q: expected 3 0 auto moves got %d %d", mode, afterMove, onDemand)
+			}
+		}
+	}
+}
+
+// go test -run FoundationTint
+func TestFoundationTint(t *testing.T) {
+	if r, g, b := foundationTint(FC); g <= r || g <= b {
+		t.Errorf("expected green clubs")
+	}
+	if r, g, b := foundationTint(FD); b <= r || b <= g {
+		t.Errorf("expected blue diamonds")
+	}
+	if r, g, b := foundationTint(FH); r <= g || r <= b {
+		t.Errorf("expected red hearts")
+	}
+	if r, g, b := foundationTint(FS); r != g || g != b {
+		t.Errorf("expected grey spades")
+	}
+	if r, _, _ := foundationTint(FS); r < 0.5 || r >= 1 {
+		t.Errorf("expected a subtle tint got %.2f", r)
+	}
+}
+
+// go test -run ActiveElapsed
+func TestActiveElapsed(t *testing.T) {
+	start := time.Now()
+	idle := 30 * time.Second
+	tests := []struct {
+		since time.Duration // time since the last input.
+		want  time.Duration
+	}{
+		{0, 0},
+		{10 * time.Second, 10 * time.Second},
+		{30 * time.Second, 30 * time.Second},
+		{5 * time.Minute, 30 * time.Second}, // the clock paused when idle.
+		{-time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := activeElapsed(start.Add(tt.since), start, idle); got != tt.want {
+			t.Errorf("since %s: expected %s got %s", tt.since, tt.want, got)
+		}
+	}
+
+	// the active clock resumes on the next input.
+	gm := &game{save: &Save{ActiveClock: true}, gameStart: start, lastInput: start}
+	gm.trackInput(start.Add(20 * time.Second))
+	gm.trackInput(start.Add(10 * time.Minute)) // idle.
+	if got := gm.gameClock(start.Add(10*time.Minute + 5*time.Second)); got != 55*time.Second {
+		t.Errorf("expected 55s of active time got %s", got)
+	}
+	gm.save.ActiveClock = false
+	if got := gm.gameClock(start.Add(10 * time.Minute)); got != 10*time.Minute {
+		t.Errorf("expected 10m of wall clock time got %s", got)
+	}
+	if got := clockText(125 * time.Second); got != "time 2:05" {
+		t.Errorf("unexpected clock text %q", got)
+	}
+}
+
+// mockCard counts the card entity calls.
+type mockCard struct{ calls int }
+
+func (m *mockCard) SetAt(x, y, z float64) *vu.Entity                 { m.calls++; return nil }
+func (m *mockCard) SetAa(x, y, z, angleInRadians float64) *vu.Entity { m.calls++; return nil }
+func (m *mockCard) SetColor(r, g, b, a float64) *vu.Entity           { m.calls++; return nil }
+func (m *mockCard) Cull(culled bool)                                 { m.calls++ }
+
+// go test -run DrawCard
+func TestDrawCard(t *testing.T) {
+	cards := [52]mockCard{}
+	drawn := [52]cardState{}
+	draw := func(states [52]cardState, force bool) (updated []uint) {
+		for cid := range states {
+			before := cards[cid].calls
+			drawCard(&cards[cid], &drawn[cid], states[cid], force)
+			if cards[cid].calls != before {
+				updated = append(updated, uint(cid))
+			}
+		}
+		return updated
+	}
+
+	// the first draw updates all the cards.
+	gold := highlightRGB(highlightGold)
+	l := &logic{}
+	l.NewGame(1)
+	if updated := draw(cardStates(l.Board(), nil, false, gold), false); len(updated) != 52 {
+		t.Fatalf("expected 52 updated cards got %d", len(updated))
+	}
+	if updated := draw(cardStates(l.Board(), nil, false, gold), false); len(updated) != 0 {
+		t.Errorf("expected no updates got %v", updated)
+	}
+
+	// only the selected card changes color.
+	l.Interact(H6)
+	if updated := draw(cardStates(l.Board(), l.GetSelected(), false, gold), false); len(updated) != 1 || updated[0] != H6 {
+		t.Errorf("expected 6H update got %v", updated)
+	}
+
+	// only the moved cards are updated after a move.
+	l.Interact(EMPTY_PILE1)
+	l.Interact(D3)
+	l.Interact(EMPTY_PILE1 + 1)
+	if updated := draw(cardStates(l.Board(), nil, false, gold), false); len(updated) != 2 || updated[0] != D3 || updated[1] != H6 {
+		t.Errorf("expected 3D and 6H updates got %v", updated)
+	}
+
+	// hidden foundation cards are only culled once.
+	board := l.Board()
+	board[AC] = FC + HIDDEN_CARD
+	if updated := draw(cardStates(board, nil, false, gold), false); len(updated) != 1 || updated[0] != AC {
+		t.Errorf("expected AC update got %v", updated)
+	}
+	if updated := draw(cardStates(board, nil, false, gold), false); len(updated) != 0 {
+		t.Errorf("expected no updates got %v", updated)
+	}
+
+	// a forced redraw updates all the cards.
+	if updated := draw(cardStates(board, nil, false, gold), true); len(updated) != 52 {
+		t.Errorf("expected 52 updated cards got %d", len(updated))
+	}
+}
+
+// go test -run StatsText
+func TestStatsText(t *testing.T) {
+	tests := []struct {
+		stats  Stats
+		played time.Duration
+		want   string
+	}{
+		{Stats{}, 0, "W:0 Streak:0 0h00m"},
+		{Stats{Wins: 12, Streak: 3}, 65*time.Minute + 59*time.Second, "W:12 Streak:3 1h05m"},
+		{Stats{Wins: 1234, Streak: 567}, 10 * time.Hour, "W:1234 Streak:567 10h00m"},
+	}
+	for _, tt := range tests {
+		if got := statsText(tt.stats, tt.played); got != tt.want {
+			t.Errorf("expected %q got %q", tt.want, got)
+		}
+		if got := statsText(tt.stats, tt.played); len(got) > 24 {
+			t.Errorf("%q does not fit the stats label", got)
+		}
+	}
+}
+
+// go test -run CustomFace
+func TestCustomFace(t *testing.T) {
+	dir := t.TempDir()
+	size := image.Point{382, 592}
+	writeFace := func(name string, w, h int) {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFace("AC.png", size.X, size.Y)
+	writeFace("AD.png", size.X-1, size.Y)
+
+	// capture the rejection log.
+	var log bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&log, nil)))
+
+	if face := customFace(dir, "AC.png", size); face == nil || face.Bounds().Size() != size {
+		t.Errorf("expected the custom AC face")
+	}
+	if face := customFace(dir, "AD.png", size); face != nil {
+		t.Errorf("expected the wrong size AD face to be rejected")
+	}
+	if !strings.Contains(log.String(), "AD.png") {
+		t.Errorf("expected the rejected face to be logged: %q", log.String())
+	}
+	if face := customFace(dir, "AH.png", size); face != nil {
+		t.Errorf("expected no custom AH face")
+	}
+}
+
+// go test -run CardFaceNames
+func TestCardFaceNames(t *testing.T) {
+	if len(cardFaceNames) != len(deck)+5 {
+		t.Fatalf("expected %d faces got %d", len(deck)+5, len(cardFaceNames))
+	}
+	for i, card := range deck {
+		if card.ID != uint(i) {
+			t.Errorf("%s: expected texture card%d for card %d", card.Sym, card.ID, i)
+		}
+		if want := card.Sym + ".png"; cardFaceNames[i] != want {
+			t.Errorf("card%d: expected face %s got %s", i, want, cardFaceNames[i])
+		}
+	}
+	for pile, want := range map[uint]string{52: "empty.png", 53: "FC.png", 54: "FD.png", 55: "FH.png", 56: "FS.png"} {
+		if cardFaceNames[pile] != want {
+			t.Errorf("card%d: expected face %s got %s", pile, want, cardFaceNames[pile])
+		}
+	}
+	for _, name := range cardFaceNames {
+		if _, err := assets.ReadFile("assets/images/" + name); err != nil {
+			t.Errorf("missing face %s: %v", name, err)
+		}
+	}
+}
+
+// go test -run FixedCardSize
+func TestFixedCardSize(t *testing.T) {
+	cardWorldHeight := cardHeight * cardScale
+	for _, wh := range []float64{480, 900, 1080, 2160} {
+		d := fixedCamDistance(wh)
+		visible := 2 * d * math.Tan(lin.Rad(90)*0.5) // world height seen at the cards.
+		if pixels := cardWorldHeight / visible * wh; math.Abs(pixels-fixedCardHeight) > 0.001 {
+			t.Errorf("window height %.0f: expected card height %.0f pixels got %f", wh, fixedCardHeight, pixels)
+		}
+	}
+}
+
+// go test -run CardLabels
+func TestCardLabels(t *testing.T) {
+	// a font atlas with a solid glyph for every card symbol character.
+	atlas := &load.FontAtlas{NRGBA: image.NewNRGBA(image.Rect(0, 0, 32, 32))}
+	draw.Draw(atlas.NRGBA, atlas.NRGBA.Bounds(), image.NewUniform(color.NRGBA{255, 255, 255, 255}), image.Point{}, draw.Src)
+	for _, r := range "A23456789TJQKCDHS" {
+		atlas.Glyphs = append(atlas.Glyphs, load.Glyph{Char: r, W: 20, H: 30, Xa: 22})
+	}
+	white := image.NewUniform(color.NRGBA{255, 255, 255, 255})
+	for _, c := range deck {
+		face := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+		draw.Draw(face, face.Bounds(), white, image.Point{}, draw.Src)
+		labelled := labelCardFace(face, atlas, c)
+		if face.NRGBAAt(20, 10) != white.C {
+			t.Fatalf("card %s: expected the original face to be unchanged", c.Sym)
+		}
+		want := color.NRGBA{0, 0, 0, 255}
+		if c.Color == RED {
+			want = color.NRGBA{200, 0, 0, 255}
+		}
+		for i := range len(c.Sym) {
+			if got := labelled.NRGBAAt(20+i*22, 10); got != want {
+				t.Errorf("card %s: expected label character %d in %v got %v", c.Sym, i, want, got)
+			}
+		}
+	}
+}
+
+// go test -run CardTextures
+func TestCardTextures(t *testing.T) {
+	if cardTextures(false) != plainCardTextures || cardTextures(true) != labelledCardTextures {
+		t.Errorf("expected a texture set for each card label preference")
+	}
+	if plainCardTextures == labelledCardTextures {
+		t.Errorf("expected the labelled cards to use their own textures")
+	}
+}
+
+// go test -run IdleFrameLimit
+func TestIdleFrameLimit(t *testing.T) {
+	now := time.Now()
+	if isIdle(now, now.Add(-idleFrameDelay/2), false) {
+		t.Errorf("expected recent input to be active")
+	}
+	if !isIdle(now, now.Add(-idleFrameDelay), false) {
+		t.Errorf("expected no input to be idle")
+	}
+	if isIdle(now, now.Add(-idleFrameDelay), true) {
+		t.Errorf("expected animations to be active")
+	}
+	for _, tc := range []struct {
+		player int
+		idle   bool
+		want   int
+	}{
+		{0, false, maxFrameLimit}, // no player cap.
+		{0, true, idleFrameLimit}, // idle lowers the rate.
+		{60, false, 60},           // player cap.
+		{60, true, idleFrameLimit},
+		{10, false, maxFrameLimit}, // outside the engine range.
+		{500, false, maxFrameLimit},
+	} {
+		if got := frameLimit(tc.player, tc.idle); got != tc.want {
+			t.Errorf("player %d idle %t: expected %d got %d", tc.player, tc.idle, tc.want, got)
+		}
+	}
+}
+
+// go test -run IdleInvite
+func TestIdleInvite(t *testing.T) {
+	now := time.Now()
+	if isInviteIdle(now, now.Add(-idleInviteDelay+time.Second), false) {
+		t.Errorf("expected no invite before the idle delay")
+	}
+	if !isInviteIdle(now, now.Add(-idleInviteDelay), false) {
+		t.Errorf("expected an invite after the idle delay")
+	}
+	if isInviteIdle(now, now.Add(-idleInviteDelay), true) {
+		t.Errorf("expected no invite while busy")
+	}
+	if bob := inviteOffset(idleInviteDelay); bob != 0 {
+		t.Errorf("expected the invite to start at rest got %f", bob)
+	}
+	if bob := inviteOffset(idleInviteDelay + invitePeriod/2); math.Abs(bob-inviteBob) > 1e-9 {
+		t.Errorf("expected the invite to peak at %f got %f", inviteBob, bob)
+	}
+
+	// the invited card is a hint card that can be moved.
+	l := &logic{}
+	l.NewGame(1)
+	move, ok := l.Hint()
+	if !ok || !l.CanMoveTo(move[0], move[1]) {
+		t.Fatalf("expected a legal hint got %s to %d %t", getCard(move[0]).Sym, move[1], ok)
+	}
+
+	// foundation moves are preferred.
+	for _, mv := range seed1Script[:2] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+	}
+	move, ok = l.Hint()
+	onFoundation := isCard(move[1]) && l.isFoundation(l.board[move[1]])
+	toFoundation := move[1] >= EMPTY_PILE1 && l.isFoundation(move[1]-EMPTY_PILE1)
+	if !ok || !(onFoundation || toFoundation) {
+		t.Errorf("expected a foundation hint got %s to %d", getCard(move[0]).Sym, move[1])
+	}
+}
+
+// go test -run SeedDebounce
+func TestSeedDebounce(t *testing.T) {
+	sd := seedDebounce{}
+	now := time.Now()
+	if _, ok := sd.commit(now); ok {
+		t.Errorf("expected nothing to commit")
+	}
+
+	// a burst of presses only commits the final seed, once.
+	seed := uint(10)
+	for i := range 5 {
+		seed = sd.shown(seed) + 1
+		sd.change(seed, now.Add(time.Duration(i)*seedQuietTime/2))
+	}
+	last := now.Add(2 * seedQuietTime)
+	commits := 0
+	for tick := now; tick.Before(last.Add(2 * seedQuietTime)); tick = tick.Add(10 * time.Millisecond) {
+		if got, ok := sd.commit(tick); ok {
+			commits++
+			if got != 15 || tick.Before(last.Add(seedQuietTime)) {
+				t.Errorf("expected seed 15 after the burst got %d at %s", got, tick.Sub(last))
+			}
+		}
+	}
+	if commits != 1 || sd.shown(15) != 15 {
+		t.Errorf("expected one commit got %d", commits)
+	}
+
+	// direct seed changes cancel pending seeds.
+	sd.change(20, now)
+	sd.cancel()
+	if _, ok := sd.commit(now.Add(seedQuietTime)); ok || sd.shown(7) != 7 {
+		t.Errorf("expected the pending seed to be cancelled")
+	}
+}
+
+// go test -run RankedDeal
+func TestRankedDeal(t *testing.T) {
+	l := &logic{}
+	unsolvable := UnsolvableGames[0]
+	if unranked, warning := rankedDeal(true, l.IsGameSolvable(unsolvable)); !unranked || warning == "" {
+		t.Errorf("expected a warning for unsolvable game %d", unsolvable)
+	}
+	if unranked, warning := rankedDeal(true, l.IsGameSolvable(1)); unranked || warning != "" {
+		t.Errorf("expected solvable games to be ranked")
+	}
+	if unranked, warning := rankedDeal(false, l.IsGameSolvable(unsolvable)); unranked || warning != "" {
+		t.Errorf("expected no warning outside ranked play")
+	}
+}
+
+// go test -run CardShadow
+func TestCardShadow(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	states := cardStates(l.Board(), nil, false, highlightRGB(highlightGold))
+	for cid, card := range states {
+		s := shadowState(card, true)
+		if s.culled || s.x <= card.x || s.y >= card.y || s.tilt != card.tilt {
+			t.Fatalf("card %d: expected the shadow down and right of %+v got %+v", cid, card, s)
+		}
+
+		// the shadow is behind its card, but in front of the card it overlaps.
+		bid := l.board[cid]
+		if s.z >= card.z || (bid >= 16 && s.z <= states[l.cardAt(bid-8)].z) {
+			t.Fatalf("card %d: expected the shadow between the cards got z %f card z %f", cid, s.z, card.z)
+		}
+	}
+	if s := shadowState(states[AC], true); s.r > 0.2 || s.a <= 0 || s.a >= 1 || states[AC].a != 1 {
+		t.Errorf("expected a dark translucent shadow for an opaque card got %+v", s)
+	}
+	if s := shadowState(states[AC], false); !s.culled {
+		t.Errorf("expected no shadows when disabled")
+	}
+
+	// the shadow shader must apply the shadow color.
+	frag, err := os.ReadFile(filepath.Join("assets", "shaders", strings.TrimPrefix(shadowShader, "shd:")+".frag"))
+	if err != nil || !bytes.Contains(frag, []byte("mu.color")) {
+		t.Errorf("expected %s to use the model color: %v", shadowShader, err)
+	}
+	if s := shadowState(cardState{culled: true, valid: true}, true); !s.culled {
+		t.Errorf("expected no shadows for hidden cards")
+	}
+}
+
+// go test -run ConfirmNewGame
+func TestConfirmNewGame(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	if p := progressOf(l); p != (gameProgress{}) || shouldConfirmNewGame(p) {
+		t.Errorf("expected a fresh deal to be left without asking got %+v", p)
+	}
+
+	// early moves are not worth protecting, deep games are.
+	confirmed := -1
+	for i, mv := range seed1Script {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+		if confirmed < 0 && shouldConfirmNewGame(progressOf(l)) {
+			confirmed = i
+		}
+	}
+	if confirmed < 2 {
+		t.Errorf("expected the first few moves to be left without asking, confirmed at %d", confirmed)
+	}
+	if !l.IsGameWon() || !shouldConfirmNewGame(progressOf(l)) {
+		t.Errorf("expected a deep game to be confirmed")
+	}
+
+	// either threshold is enough.
+	for _, tc := range []struct {
+		p    gameProgress
+		want bool
+	}{
+		{gameProgress{moves: confirmMoves - 1, foundations: confirmFoundations - 1}, false},
+		{gameProgress{moves: confirmMoves}, true},
+		{gameProgress{moves: 3, foundations: confirmFoundations}, true},
+	} {
+		if got := shouldConfirmNewGame(tc.p); got != tc.want {
+			t.Errorf("%+v: expected %t got %t", tc.p, tc.want, got)
+		}
+	}
+}
+
+// go test -run CameraDistance
+func TestCameraDistance(t *testing.T) {
+	gm := &game{}
+	left, _, _ := placeCard(0)
+	right, _, _ := placeCard(7)
+	boardWidth := right - left + cardWidth*cardScale
+	for _, tc := range []struct {
+		device string
+		ratio  float64 // width over height.
+		want   float64 // camera distance from the cards.
+	}{
+		{"iphone 9:16", 9.0 / 16.0, 5.7621},
+		{"ipad 3:4", 3.0 / 4.0, 4.3216},
+		{"ipad mini 2:3", 2.0 / 3.0, 4.8618},
+	} {
+		// same arguments as the camera placement when the window resizes.
+		got := gm.camToBoardDistance(10.5, 0.0, 90.0, tc.ratio)
+		if math.Abs(got-tc.want) > 0.0001 {
+			t.Errorf("%s: expected camera distance %.4f got %.4f", tc.device, tc.want, got)
+		}
+
+		// the engine camera has a 90 degree vertical field of view.
+		visible := 2 * (got - cardZ) * math.Tan(lin.Rad(45)) * tc.ratio
+		if visible < boardWidth {
+			t.Errorf("%s: board width %.3f clipped to %.3f", tc.device, boardWidth, visible)
+		}
+	}
+}
+
+// go test -run CardsRemaining
+func TestCardsRemaining(t *testing.T) {
+	if got := cardsRemaining([4][]uint{}); got != 52 {
+		t.Errorf("expected 52 cards for empty foundations got %d", got)
+	}
+	mixed := [4][]uint{{AC, C2, C3}, {}, {AH}, {AS, S2, S3, S4, S5, S6, S7, S8, S9, TS, JS, QS, KS}}
+	if got := cardsRemaining(mixed); got != 35 {
+		t.Errorf("expected 35 cards got %d", got)
+	}
+
+	// matches the cards moved to the foundations while playing.
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+		onFoundations := 0
+		for _, bid := range l.Board() {
+			if bid >= FC && bid <= FC+SPD || bid > HIDDEN_CARD {
+				onFoundations++
+			}
+		}
+		if got := cardsRemaining(l.Foundations()); got != 52-onFoundations {
+			t.Fatalf("move %d: expected %d cards got %d", l.MoveNumber(), 52-onFoundations, got)
+		}
+	}
+	if got := cardsRemaining(l.Foundations()); got != 0 {
+		t.Errorf("expected no cards remaining after a win got %d", got)
+	}
+}
+
+// go test -run MoveLog
+func TestMoveLog(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	if lines := moveLogText(l.moves.stack, moveLogRows); len(lines) != 0 {
+		t.Errorf("expected no moves for a new deal got %v", lines)
+	}
+	for _, mv := range seed1Script[:5] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+
+	// free cell, foundation, and cascade moves, including auto moves.
+	want := []string{"AS->F", "8C->free", "JH->QC", "4D->free", "AH->F", "2H->F"}
+	if lines := moveLogText(l.moves.stack, len(want)); !slices.Equal(lines, want) {
+		t.Errorf("expected %v got %v", want, lines)
+	}
+	if lines := moveLogText(l.moves.stack, 3); !slices.Equal(lines, want[3:]) {
+		t.Errorf("expected the latest 3 moves %v got %v", want[3:], lines)
+	}
+}
+
+// go test -run PileLayout
+func TestPileLayout(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	cam := orthoCam{}
+	column := func(col uint) (mx, my int) {
+		x, y, z := placePile(col) // top row columns in the classic layout.
+		return cam.Screen(x, y, z, 800, 1000)
+	}
+	leftX, leftY := column(0)
+	rightX, rightY := column(7)
+	defer func() { pileSlots = pileLayoutSlots(pileLayoutClassic) }()
+
+	// the leftmost column picks the club foundation, the rightmost the last free cell.
+	pileSlots = pileLayoutSlots(pileLayoutFoundationsLeft)
+	if pick := hitCard(cam, l.Board(), false, 800, 1000, leftX, leftY); pick != EMPTY_PILE1+FC {
+		t.Errorf("expected the empty club foundation got %d", pick)
+	}
+	if pick := hitCard(cam, l.Board(), false, 800, 1000, rightX, rightY); pick != EMPTY_PILE1+3 {
+		t.Errorf("expected the last empty free cell got %d", pick)
+	}
+	board := l.Board()
+	board[AC] = FC
+	if pick := hitCard(cam, board, false, 800, 1000, leftX, leftY); pick != AC {
+		t.Errorf("expected AC on the club foundation got %d", pick)
+	}
+
+	// mirrored puts the spade foundation on the far left.
+	pileSlots = pileLayoutSlots(pileLayoutMirrored)
+	if pick := hitCard(cam, l.Board(), false, 800, 1000, leftX, leftY); pick != EMPTY_PILE1+FS {
+		t.Errorf("expected the empty spade foundation got %d", pick)
+	}
+
+	// each layout uses every column once.
+	for _, layout := range pileLayouts {
+		slots := pileLayoutSlots(layout)
+		seen := map[uint]bool{}
+		for _, col := range slots {
+			seen[col] = true
+		}
+		if len(seen) != 8 {
+			t.Errorf("%s: expected 8 columns got %v", layout, slots)
+		}
+	}
+}
+
+// colorCard is a mock card that remembers its color.
+type colorCard struct {
+	mockCard
+	rgb [3]float64
+}
+
+func (m *colorCard) SetColor(r, g, b, a float64) *vu.Entity {
+	m.rgb = [3]float64{r, g, b}
+	return m.mockCard.SetColor(r, g, b, a)
+}
+
+// go test -run HighlightColor
+func TestHighlightColor(t *testing.T) {
+	if highlightRGB(highlightGold) != [3]float64{1.0, 0.8, 0.0} {
+		t.Errorf("expected gold to be the original selection color")
+	}
+	if got := highlightRGB("#ff8000"); got != [3]float64{1, 128.0 / 255, 0} {
+		t.Errorf("expected a custom color, got %v", got)
+	}
+	for _, bad := range []string{"", "teal", "#ff80", "#ff8000ff", "#gg0000"} {
+		if highlightRGB(bad) != highlightRGB(highlightGold) {
+			t.Errorf("expected %q to fall back to gold", bad)
+		}
+	}
+	for _, preset := range highlightColors[1:] {
+		if highlightRGB(preset) == highlightRGB(highlightGold) {
+			t.Errorf("expected a color for the %s preset", preset)
+		}
+	}
+
+	// the selected cards are drawn in the configured color.
+	s := newSave(t.TempDir(), "freecell.save")
+	s.HighlightColor = highlightCyan
+	l := &logic{}
+	l.NewGame(1)
+	l.Interact(H6)
+	cards, drawn := [52]colorCard{}, [52]cardState{}
+	for cid, state := range cardStates(l.Board(), l.GetSelected(), false, highlightRGB(s.HighlightColor)) {
+		drawCard(&cards[cid], &drawn[cid], state, false)
+	}
+	for cid := range cards {
+		want := [3]float64{1, 1, 1}
+		if uint(cid) == H6 {
+			want = highlightRGB(highlightCyan)
+		}
+		if cards[cid].rgb != want {
+			t.Errorf("%s: expected color %v, got %v", deck[cid].Sym, want, cards[cid].rgb)
+		}
+	}
+
+	// the color only changes during play, so that it is never drawn
+	// over a game seed that is being typed or dialed.
+	gm := &game{save: s, logic: l, state: SelectState}
+	gm.nextHighlight()
+	if s.HighlightColor != highlightCyan {
+		t.Errorf("expected the color to stay cyan got %s", s.HighlightColor)
+	}
+}
+
+// go test -run BackgroundTicker
+func TestBackgroundTicker(t *testing.T) {
+	start := time.Now()
+	if got := backgroundTicker(start.Add(3*time.Second), start, time.Time{}); got != 3 {
+		t.Errorf("expected the background to move during play got %f", got)
+	}
+
+	// the ticker stops advancing once the game is won.
+	won := start.Add(5 * time.Second)
+	for _, later := range []time.Duration{0, time.Second, time.Minute} {
+		if got := backgroundTicker(won.Add(later), start, won); got != 5 {
+			t.Errorf("expected the background to stop when won got %f", got)
+		}
+	}
+}
+
+// go test -run HighContrast
+func TestHighContrast(t *testing.T) {
+	for _, seed := range []uint{1, 2, 617, MAX_SEED} {
+		if r, g, b := boardColor(seed, true); [3]float64{r, g, b} != highContrastBoard {
+			t.Errorf("seed %d: expected the high contrast board got %f %f %f", seed, r, g, b)
+		}
+		r, g, b := boardColor(seed, false)
+		if gr, gg, gb := gameColor(seed); r != gr || g != gg || b != gb {
+			t.Errorf("seed %d: expected the seed color", seed)
+		}
+	}
+
+	// drop highlights are stronger.
+	r, g, _ := hoverColor(true, false)
+	hr, hg, _ := hoverColor(true, true)
+	if hg != g || hr >= r {
+		t.Errorf("expected a stronger green got %f %f", hr, hg)
+	}
+	r, g, _ = hoverColor(false, false)
+	hr, hg, _ = hoverColor(false, true)
+	if hr != r || hg >= g {
+		t.Errorf("expected a stronger red got %f %f", hr, hg)
+	}
+}
@@ -0,0 +1,286 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// input.go turns raw keyboard, mouse, and touch input into player intents.
+// The game dispatches intents rather than checking the input directly.
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gazed/vu"
+)
+
+// intent is a player action decoded from the input.
+type intent int
+
+// player intents.
+const (
+	quitIntent       intent = iota // quit the game.
+	fullscreenIntent               // toggle fullscreen.
+	nextGameIntent                 // change to the next game seed.
+	prevGameIntent                 // change to the previous game seed.
+	undoIntent                     // undo the last move.
+	celebrateIntent                // play the end game effect.
+	autoMoveIntent                 // move the safe cards to the foundations.
+	resetIntent                    // reset the scores after confirming.
+	practiceIntent                 // change to the hardest unwon game.
+	statsIntent                    // toggle the statistics display.
+	moveNumberIntent               // toggle the move number display.
+	bookmarkIntent                 // bookmark the current position.
+	restoreIntent                  // restore the furthest bookmark.
+	hardGameIntent                 // change to a random hard game.
+	exportIntent                   // export the statistics to a file.
+	lockIntent                     // toggle the seed lock.
+	favoriteIntent                 // toggle the current game as a favorite.
+	browseIntent                   // list the favorite and recent games.
+	blockersIntent                 // toggle highlighting the cards blocking the aces.
+	importIntent                   // play the layout in the import file.
+	labelsIntent                   // toggle the large card labels.
+	variantIntent                  // switch to the next rules variant between games.
+	remainingIntent                // toggle the cards remaining display.
+	moveLogIntent                  // toggle the recent moves display.
+	windowIntent                   // reset the window location and size.
+	pileLayoutIntent               // switch where the free cells and foundations are drawn.
+	highlightIntent                // switch to the next selection color.
+	undoCheckIntent                // check if the last move should be undone.
+	contrastIntent                 // toggle the high contrast theme.
+	scoreboardIntent               // list the completed games.
+	repeatIntent                   // toggle dealing the current game again until it is won.
+	swipeUndoIntent                // mouse or touch swipe left: undo the last move.
+	pressIntent                    // mouse or touch press, ie: buttons.
+	pickIntent                     // mouse or touch tap: pick or place cards.
+	holdIntent                     // mouse or touch held down.
+	releaseIntent                  // mouse or touch released, or another key pressed.
+	digitIntent                    // digit key typed while selecting a game.
+	cancelIntent                   // other key pressed while selecting a game.
+	focusUpIntent                  // move the list focus up.
+	focusDownIntent                // move the list focus down.
+	chooseIntent                   // choose the focused game in a list.
+	closeIntent                    // close a list.
+	sortIntent                     // switch the scoreboard sort order.
+	parIntent                      // toggle only listing the games under par.
+)
+
+// keyIntents are the key bindings available in all game states.
+// F11 is the standard window key for toggling fullscreen. F is also
+// commonly used. macos Ctrl-Cmd-F is handled automatically by the
+// macos window manager.
+var keyIntents = map[int32]intent{
+	vu.KQ:      quitIntent,
+	vu.KF11:    fullscreenIntent,
+	vu.KF:      fullscreenIntent,
+	vu.KARight: nextGameIntent,
+	vu.KALeft:  prevGameIntent,
+	vu.KT:      celebrateIntent,
+	vu.KA:      autoMoveIntent,
+	vu.KR:      resetIntent,
+	vu.KH:      practiceIntent,
+	vu.KS:      statsIntent,
+	vu.KM:      moveNumberIntent,
+	vu.KB:      bookmarkIntent,
+	vu.KG:      restoreIntent,
+	vu.KD:      hardGameIntent,
+	vu.KE:      exportIntent,
+	vu.KL:      lockIntent,
+	vu.KV:      favoriteIntent,
+	vu.KO:      browseIntent,
+	vu.KC:      blockersIntent,
+	vu.KF5:     importIntent,
+	vu.KF6:     labelsIntent,
+	vu.KW:      variantIntent,
+	vu.KI:      remainingIntent,
+	vu.KJ:      moveLogIntent,
+	vu.KP:      windowIntent,
+	vu.KK:      pileLayoutIntent,
+	vu.KN:      highlightIntent,
+	vu.KX:      undoCheckIntent,
+	vu.KF2:     contrastIntent,
+	vu.KF3:     scoreboardIntent,
+	vu.KF4:     repeatIntent,
+}
+
+// browseKeys are the key bindings while the browse list is open.
+var browseKeys = map[int32]intent{
+	vu.KAUp:   focusUpIntent,
+	vu.KADown: focusDownIntent,
+	vu.KRet:   chooseIntent,
+	vu.KEsc:   closeIntent,
+}
+
+// scoreboardKeys are the key bindings while the scoreboard is open.
+var scoreboardKeys = map[int32]intent{
+	vu.KAUp:   focusUpIntent,
+	vu.KADown: focusDownIntent,
+	vu.KRet:   chooseIntent,
+	vu.KEsc:   closeIntent,
+	vu.KS:     sortIntent,
+	vu.KP:     parIntent,
+}
+
+// keyPresses returns the intents for the one time key presses in key
+// order. Keys without a binding are returned separately.
+func keyPresses(pressed map[int32]bool) (intents []intent, unbound []int32) {
+	keys := []int32{}
+	for key := range pressed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if it, ok := keyIntents[key]; ok {
+			intents = append(intents, it)
+			continue
+		}
+		unbound = append(unbound, key)
+	}
+	return intents, unbound
+}
+
+// isPointer returns true for the mouse button and touch inputs.
+func isPointer(key int32) bool { return key == vu.KML || key == vu.TOUCH }
+
+// isDigit returns true for the keyboard and keypad digit keys.
+func isDigit(key int32) bool {
+	switch key {
+	case vu.K0, vu.K1, vu.K2, vu.K3, vu.K4, vu.K5, vu.K6, vu.K7, vu.K8, vu.K9,
+		vu.KP0, vu.KP1, vu.KP2, vu.KP3, vu.KP4, vu.KP5, vu.KP6, vu.KP7, vu.KP8, vu.KP9:
+		return true
+	}
+	return false
+}
+
+// pointer tracks a mouse or touch press to decide if it is a tap.
+type pointer struct {
+	x, y    int       // where the current press started.
+	pressed bool      // true while a press is active.
+	down    time.Time // when the held press started.
+}
+
+// intents returns the mouse or touch intents for regular game play.
+// Presses happen immediately and cards are picked on the press, unless
+// there is a tap tolerance. With a tolerance cards are picked on release
+// as long as the press was a tap. Presses that move further than the
+// tolerance are drags and don't pick cards. Drags that move sideways
+// further than the swipe threshold are swipes.
+func (p *pointer) intents(in *vu.Input, tolerance, threshold int) (intents []intent) {
+	mx, my := int(in.Mx), int(in.My)
+	for press := range in.Pressed {
+		if isPointer(press) {
+			p.x, p.y, p.pressed = mx, my, true
+			intents = append(intents, pressIntent)
+			if tolerance <= 0 {
+				intents = append(intents, pickIntent)
+			}
+			break
+		}
+	}
+	for release := range in.Released {
+		if isPointer(release) && p.pressed {
+			p.pressed = false
+			if tolerance > 0 && isTap(p.x, p.y, mx, my, tolerance) {
+				intents = append(intents, pickIntent)
+			}
+			// FUTURE: swipe right to redo once redos are supported.
+			if swipeDirection(p.x, p.y, mx, my, threshold) == swipeLeft {
+				intents = append(intents, swipeUndoIntent)
+			}
+			break
+		}
+	}
+	for press, start := range in.Down {
+		if isPointer(press) {
+			p.down = start
+			intents = append(intents, holdIntent)
+			break
+		}
+	}
+	return intents
+}
+
+// selectIntents returns the intents, in key order, while a game number
+// is typed: digitIntent for each digit key and cancelIntent for any other
+// key. The key for each intent is returned with the intents.
+func selectIntents(pressed map[int32]bool) (intents []intent, keys []int32) {
+	for key := range pressed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if isDigit(key) {
+			intents = append(intents, digitIntent)
+			continue
+		}
+		intents = append(intents, cancelIntent)
+	}
+	return intents, keys
+}
+
+// listIntents returns the intents, in key order, for the keys pressed
+// while a list is open. Keys without a binding are ignored.
+func listIntents(pressed map[int32]bool, bindings map[int32]intent) (intents []intent) {
+	keys := []int32{}
+	for key := range pressed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if it, ok := bindings[key]; ok {
+			intents = append(intents, it)
+		}
+	}
+	return intents
+}
+
+// dialIntents returns the intent while the seed speed dial is active:
+// holdIntent while only the mouse button or a touch is held down, and
+// releaseIntent once it is released or any other key is held.
+func dialIntents(in *vu.Input) []intent {
+	if !pointerDown(in) {
+		return []intent{releaseIntent}
+	}
+	for key := range in.Down {
+		if !isPointer(key) {
+			return []intent{releaseIntent}
+		}
+	}
+	return []intent{holdIntent}
+}
+
+// pointerDown returns true if the mouse button or a touch is held down.
+func pointerDown(in *vu.Input) bool {
+	_, mouse := in.Down[vu.KML]
+	_, touch := in.Down[vu.TOUCH]
+	return mouse || touch
+}
+
+// isTap returns true if a press that started at sx, sy and was released
+// at ex, ey moved no more than the tolerance in pixels. Otherwise the
+// press is a drag.
+func isTap(sx, sy, ex, ey, tolerance int) bool {
+	dx, dy := ex-sx, ey-sy
+	return dx*dx+dy*dy <= tolerance*tolerance
+}
+
+// swipe directions.
+const (
+	swipeNone  = iota // not a swipe.
+	swipeLeft         // moved right to left.
+	swipeRight        // moved left to right.
+)
+
+// swipeDirection returns the direction of a press that started at sx, sy
+// and was released at ex, ey. A swipe moves sideways at least threshold
+// pixels and mostly horizontally, so that vertical drags are ignored.
+// A threshold of zero disables swipes.
+func swipeDirection(sx, sy, ex, ey, threshold int) int {
+	dx, dy := ex-sx, ey-sy
+	if threshold <= 0 || dx*dx < threshold*threshold || dx*dx <= 4*dy*dy {
+		return swipeNone
+	}
+	if dx < 0 {
+		return swipeLeft
+	}
+	return swipeRight
+}
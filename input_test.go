@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gazed/vu"
+)
+
+// go test -run KeyPresses
+func TestKeyPresses(t *testing.T) {
+	in := &vu.Input{Pressed: map[int32]bool{vu.KQ: true, vu.KF11: true, vu.KA: true, vu.KU: true}}
+	intents, unbound := keyPresses(in.Pressed)
+	slices.Sort(intents) // key codes are platform specific.
+	want := []intent{quitIntent, fullscreenIntent, autoMoveIntent}
+	if !slices.Equal(intents, want) {
+		t.Errorf("expected intents %v got %v", want, intents)
+	}
+	if !slices.Equal(unbound, []int32{vu.KU}) {
+		t.Errorf("expected unbound U got %v", unbound)
+	}
+	if intents, unbound = keyPresses(map[int32]bool{}); len(intents) != 0 || len(unbound) != 0 {
+		t.Errorf("expected no intents got %v %v", intents, unbound)
+	}
+}
+
+// go test -run PointerIntents
+func TestPointerIntents(t *testing.T) {
+	start := time.Now()
+	p := &pointer{}
+	check := func(in *vu.Input, want ...intent) {
+		t.Helper()
+		if got := p.intents(in, 12, 120); !slices.Equal(got, want) {
+			t.Errorf("expected %v got %v", want, got)
+		}
+	}
+
+	// a tap presses then picks on release.
+	check(&vu.Input{Mx: 100, My: 100, Pressed: map[int32]bool{vu.KML: true}, Down: map[int32]time.Time{vu.KML: start}},
+		pressIntent, holdIntent)
+	check(&vu.Input{Mx: 105, My: 100, Released: map[int32]time.Duration{vu.KML: time.Millisecond}},
+		pickIntent)
+	check(&vu.Input{Mx: 105, My: 100, Released: map[int32]time.Duration{vu.KML: time.Millisecond}})
+
+	// a touch drag doesn't pick.
+	check(&vu.Input{Mx: 100, My: 100, Pressed: map[int32]bool{vu.TOUCH: true}}, pressIntent)
+	check(&vu.Input{Mx: 100, My: 100, Down: map[int32]time.Time{vu.TOUCH: start}}, holdIntent)
+	check(&vu.Input{Mx: 200, My: 100, Released: map[int32]time.Duration{vu.TOUCH: time.Second}})
+	if p.pressed || !p.down.Equal(start) {
+		t.Errorf("expected press to end and hold to start at %v got %+v", start, p)
+	}
+
+	// a long drag to the left is a swipe.
+	check(&vu.Input{Mx: 300, My: 100, Pressed: map[int32]bool{vu.TOUCH: true}}, pressIntent)
+	check(&vu.Input{Mx: 150, My: 110, Released: map[int32]time.Duration{vu.TOUCH: time.Second}}, swipeUndoIntent)
+
+	// other keys are not pointer intents.
+	check(&vu.Input{Pressed: map[int32]bool{vu.KQ: true}, Down: map[int32]time.Time{vu.KQ: start}})
+
+	// without a tap tolerance cards are picked on the press.
+	if got := p.intents(&vu.Input{Mx: 100, My: 100, Pressed: map[int32]bool{vu.TOUCH: true}}, 0, 120); !slices.Equal(got, []intent{pressIntent, pickIntent}) {
+		t.Errorf("expected a pick on the press got %v", got)
+	}
+	if got := p.intents(&vu.Input{Mx: 100, My: 100, Released: map[int32]time.Duration{vu.TOUCH: time.Millisecond}}, 0, 120); len(got) != 0 {
+		t.Errorf("expected no pick on the release got %v", got)
+	}
+	if newSave(t.TempDir(), "freecell.save").TapTolerance != 0 {
+		t.Errorf("expected cards to be picked on the press by default")
+	}
+}
+
+// go test -run SelectIntents
+func TestSelectIntents(t *testing.T) {
+	intents, keys := selectIntents(map[int32]bool{vu.K4: true, vu.KP2: true})
+	if !slices.Equal(intents, []intent{digitIntent, digitIntent}) || len(keys) != 2 || !isDigit(keys[0]) || !isDigit(keys[1]) {
+		t.Errorf("expected two digits got %v %v", intents, keys)
+	}
+	intents, keys = selectIntents(map[int32]bool{vu.KU: true})
+	if !slices.Equal(intents, []intent{cancelIntent}) || !slices.Equal(keys, []int32{vu.KU}) {
+		t.Errorf("expected other keys to cancel got %v %v", intents, keys)
+	}
+	if intents, _ = selectIntents(map[int32]bool{}); len(intents) != 0 {
+		t.Errorf("expected no intents got %v", intents)
+	}
+}
+
+// go test -run ListIntents
+func TestListIntents(t *testing.T) {
+	intents := listIntents(map[int32]bool{vu.KADown: true, vu.KRet: true, vu.KU: true}, browseKeys)
+	slices.Sort(intents) // key codes are platform specific.
+	if want := []intent{focusDownIntent, chooseIntent}; !slices.Equal(intents, want) {
+		t.Errorf("expected %v got %v", want, intents)
+	}
+	if intents = listIntents(map[int32]bool{vu.KQ: true}, browseKeys); len(intents) != 0 {
+		t.Errorf("expected other keys to be ignored got %v", intents)
+	}
+	intents = listIntents(map[int32]bool{vu.KS: true, vu.KP: true}, scoreboardKeys)
+	slices.Sort(intents)
+	if want := []intent{sortIntent, parIntent}; !slices.Equal(intents, want) {
+		t.Errorf("expected the scoreboard keys %v got %v", want, intents)
+	}
+}
+
+// go test -run DialIntents
+func TestDialIntents(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		down map[int32]time.Time
+		want intent
+	}{
+		{map[int32]time.Time{vu.KML: start}, holdIntent},
+		{map[int32]time.Time{vu.TOUCH: start}, holdIntent},
+		{map[int32]time.Time{}, releaseIntent},
+		{map[int32]time.Time{vu.KML: start, vu.KU: start}, releaseIntent}, // another key.
+	}
+	for i, tt := range tests {
+		if got := dialIntents(&vu.Input{Down: tt.down}); !slices.Equal(got, []intent{tt.want}) {
+			t.Errorf("%d: expected %v got %v", i, tt.want, got)
+		}
+	}
+}
+
+// go test -run Tap
+func TestTap(t *testing.T) {
+	tests := []struct {
+		sx, sy, ex, ey, tolerance int
+		tap                       bool
+	}{
+		{100, 100, 100, 100, 12, true},
+		{100, 100, 108, 92, 12, true},  // a wobble.
+		{100, 100, 112, 100, 12, true}, // at the tolerance.
+		{100, 100, 109, 109, 12, false},
+		{100, 100, 60, 100, 12, false}, // a drag.
+		{100, 100, 101, 100, 0, false},
+		{100, 100, 60, 100, 50, true}, // a large tolerance.
+	}
+	for _, tt := range tests {
+		if got := isTap(tt.sx, tt.sy, tt.ex, tt.ey, tt.tolerance); got != tt.tap {
+			t.Errorf("%+v: expected tap %t", tt, tt.tap)
+		}
+	}
+}
+
+// go test -run Swipe
+func TestSwipe(t *testing.T) {
+	tests := []struct {
+		sx, sy, ex, ey, threshold int
+		dir                       int
+	}{
+		{300, 100, 150, 100, 120, swipeLeft},
+		{100, 100, 250, 100, 120, swipeRight},
+		{300, 100, 180, 100, 120, swipeLeft}, // at the threshold.
+		{300, 100, 190, 100, 120, swipeNone}, // too short.
+		{300, 100, 150, 140, 120, swipeLeft}, // a slight slant.
+		{300, 100, 150, 180, 120, swipeNone}, // too steep.
+		{100, 100, 100, 300, 120, swipeNone}, // a vertical drag.
+		{100, 100, 100, 100, 120, swipeNone}, // a tap.
+		{300, 100, 150, 100, 0, swipeNone},   // swipes disabled.
+		{300, 100, 260, 100, 30, swipeLeft},  // a small threshold.
+	}
+	for i, tt := range tests {
+		if got := swipeDirection(tt.sx, tt.sy, tt.ex, tt.ey, tt.threshold); got != tt.dir {
+			t.Errorf("%d: expected direction %d got %d", i, tt.dir, got)
+		}
+	}
+}
+
+// go test -run Numberpad
+func TestNumberpad(t *testing.T) {
+	tests := []struct {
+		pref     string
+		platform bool // platform normally has a keyboard.
+		typed    bool // a digit key was pressed.
+		want     bool
+	}{
+		{numberpadAuto, true, false, true},   // desktop.
+		{numberpadAuto, false, false, false}, // ios uses the seed dial.
+		{numberpadAuto, false, true, true},   // ipad with a keyboard.
+		{numberpadOff, true, true, false},    // player prefers the seed dial.
+		{numberpadOn, false, false, true},    // player has a soft keyboard.
+		{"", false, true, true},              // older saves are auto.
+	}
+	for i, tt := range tests {
+		if got := numberpadExists(tt.pref, tt.platform, tt.typed); got != tt.want {
+			t.Errorf("%d: expected %t got %t", i, tt.want, got)
+		}
+	}
+	if !isDigit(vu.K7) || !isDigit(vu.KP0) || isDigit(vu.KA) || isDigit(vu.KML) {
+		t.Errorf("unexpected digit keys")
+	}
+}
+
+// go test -run QuitPress
+func TestQuitPress(t *testing.T) {
+	now := time.Now()
+	if quit, _ := quitPress(false, now, time.Time{}); !quit {
+		t.Errorf("expected a single press to quit by default")
+	}
+
+	// the first press asks, and a second press soon after quits.
+	quit, expires := quitPress(true, now, time.Time{})
+	if quit || !expires.After(now) {
+		t.Fatalf("expected the first press to ask got %t %v", quit, expires)
+	}
+	if quit, _ := quitPress(true, now.Add(time.Second), expires); !quit {
+		t.Errorf("expected a quick second press to quit")
+	}
+
+	// a late second press asks again.
+	late := expires.Add(time.Millisecond)
+	if quit, next := quitPress(true, late, expires); quit || !next.After(late) {
+		t.Errorf("expected a late press to ask again got %t %v", quit, next)
+	}
+}
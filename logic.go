@@ -6,9 +6,13 @@ package main
 // logic.go contains the game rules and game state.
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand"
 	"slices"
+	"strings"
+	"time"
 )
 
 const (
@@ -100,8 +104,9 @@ const (
 	// hide cards using an invalid board location
 	// By convention HIDDEN_CARD is only used to hide foundation cards,
 	// and is added to the existing foundation board ID.
-	HIDDEN_CARD uint = 9999 // used to hide buried foundation cards.
-	NO_CARD     uint = 999  // used for empty slots
+	HIDDEN_CARD uint = 9999  // used to hide buried foundation cards.
+	NO_CARD     uint = 999   // used for empty slots
+	NO_HIT      uint = 99999 // used when a pick misses all cards and piles.
 
 	// empty piles are indicated by 100+pileID
 	EMPTY_PILE1  uint = uint(100)
@@ -113,6 +118,10 @@ const (
 
 	// 1 million games starting at game 0.
 	MAX_SEED uint = 999_999
+
+	// the original microsoft games numbered 1 to 32,000.
+	MIN_CLASSIC_SEED uint = 1
+	MAX_CLASSIC_SEED uint = 32_000
 )
 
 // Deck is a sorted deck of playing cards.
@@ -183,6 +192,20 @@ type logic struct {
 	gameSeed uint     // unique game ID.
 	deal     [52]Card // a shuffled standard playing deck of cards.
 
+	// singleCardMoves disallows sequence moves (supermoves)
+	// so that only one card is moved at a time.
+	singleCardMoves bool
+
+	// closedCells is the number of free cells that can't be used,
+	// counting down from the last free cell, ie: for fewer free cells.
+	closedCells int
+
+	// undoKeepsSelection reselects the selected card after an undo.
+	undoKeepsSelection bool
+
+	// rules of play. Nil for FreecellRules.
+	rules Rules
+
 	// Track game state by mapping each card to a board location.
 	// This encapsulates game state in a compact structure.
 	// Empty spots are marked with NO_CARD.
@@ -198,26 +221,42 @@ type logic struct {
 	//   cascade 8   15,23,31,...,167
 	board [52]uint // board locations for each card ID.
 
+	// deal difficulty ratings by seed, filled in as deals are rated.
+	ratings map[uint]int
+
+	// custom is true while playing a layout that isn't a game seed.
+	custom bool
+
 	// track player moves by saving board state after each move.
 	// Add a player move each time a card is placed.
 	// Get the previous game state each player undo.
 	// Moves moves
 	moves *moves // stack of board positions
+
+	// playerMoved is true once the player has moved a card in the
+	// current game. Auto moves wait for the first player move, even when
+	// the game starts from a position with earlier moves, ie: a bookmark.
+	playerMoved bool
 }
 
 // Start a new game of freecell based on the given game number seed.
 // Initializes the game cards from the given seed.
 // Expected to be called by the UI layer.
 func (l *logic) NewGame(seed uint) {
+	l.newDeal(seed, shuffle(seed, deck))
+	l.custom = false
+}
+
+// newDeal starts a game with the given cards in deal order.
+func (l *logic) newDeal(seed uint, deal [52]Card) {
 	l.gameSeed = seed  // remember the game number for the UI.
 	l.moves = &moves{} //
 	l.clearSelected()  // start with nothing selected.
+	l.playerMoved = false
 
-	// put the shuffled cards into the cascades.
-	l.deal = shuffle(seed, deck)
-	for cid := AC; cid <= KS; cid++ {
-		l.board[l.deal[cid].ID] = cid + 8
-	}
+	// put the cards into the cascades.
+	l.deal = deal
+	l.board = l.ruleSet().Deal(l.deal)
 
 	// save the initial board position.
 	l.moves.reset()
@@ -231,18 +270,193 @@ var UnsolvableGames = []uint{
 	495_505, 512_118, 517_776, 781_948,
 }
 
+// classicSeed returns the game seed for a classic microsoft freecell
+// game number. The shuffle reproduces the classic deals so the game
+// numbers and seeds are the same. Returns false for invalid game numbers.
+func classicSeed(game uint) (seed uint, ok bool) {
+	if game < MIN_CLASSIC_SEED || game > MAX_CLASSIC_SEED {
+		return 0, false
+	}
+	return game, true
+}
+
+// SeedForLayout returns the game seed that deals the given layout.
+// The layout is the 52 card symbols in deal order, ie: the rows of the
+// initial cascades "JD 2D 9H JC 5D 7H 7C 5H ...". Only the classic game
+// seeds are searched. Returns false for invalid layouts or if no classic
+// game matches the layout, in which case the layout is a custom deal
+// and scores can't be attributed to a seed.
+func (l *logic) SeedForLayout(layout string) (seed uint, ok bool) {
+	deal, ok := parseLayout(layout)
+	if !ok {
+		return 0, false
+	}
+
+	// shuffle is deterministic so reproduce each classic deal until
+	// one matches. This is fast enough for the classic game range.
+	for seed = MIN_CLASSIC_SEED; seed <= MAX_CLASSIC_SEED; seed++ {
+		if shuffle(seed, deck) == deal {
+			return seed, true
+		}
+	}
+	return 0, false
+}
+
+// NewCustomGame deals the given layout as a custom game that has no
+// game seed, see SeedForLayout. Returns false for invalid layouts,
+// in which case the current game is unchanged.
+func (l *logic) NewCustomGame(layout string) bool {
+	deal, ok := parseLayout(layout)
+	if !ok {
+		return false
+	}
+	l.newDeal(0, deal)
+	l.custom = true
+	return true
+}
+
+// parseLayout returns the cards for the given layout card symbols.
+// Returns false unless the layout has each of the 52 cards once.
+func parseLayout(layout string) (deal [52]Card, ok bool) {
+	syms := strings.Fields(strings.ToUpper(layout))
+	if len(syms) != len(deal) {
+		return deal, false
+	}
+	used := [52]bool{}
+	for i, sym := range syms {
+		index := slices.IndexFunc(deck[:], func(c Card) bool { return c.Sym == sym })
+		if index < 0 || used[index] {
+			return deal, false // unknown or duplicate card.
+		}
+		used[index] = true
+		deal[i] = deck[index]
+	}
+	return deal, true
+}
+
 // IsGameSolvable returns true if the given game seed can be solved.
 func (l *logic) IsGameSolvable(gameSeed uint) bool {
 	_, found := slices.BinarySearch(UnsolvableGames, gameSeed)
 	return !found
 }
 
+// DifficultyBand is a range of deal ratings, from Min up to but not
+// including Max.
+type DifficultyBand struct{ Min, Max int }
+
+// deal difficulty bands, roughly a third of the deals in each.
+var (
+	EasyDeals   = DifficultyBand{0, 40}
+	MediumDeals = DifficultyBand{40, 50}
+	HardDeals   = DifficultyBand{50, 101}
+)
+
+// seedSearchBudget limits the time spent looking for a seed.
+const seedSearchBudget = 100 * time.Millisecond
+
+// DealRating rates the deal for the given seed from 0, easy, to 100, hard.
+// The rating is based on how deeply the low cards are buried in the
+// cascades, with the aces counting the most since the foundations can't
+// be started until they are free. Unsolvable games are rated 100.
+// Ratings are cached since seed searches rate many deals.
+func (l *logic) DealRating(seed uint) int {
+	if rating, ok := l.ratings[seed]; ok {
+		return rating
+	}
+	if l.ratings == nil {
+		l.ratings = map[uint]int{}
+	}
+	rating := 100
+	if l.IsGameSolvable(seed) {
+		buried, worst := 0, 0
+		for i, c := range shuffle(seed, deck) {
+			if c.Rank > THRE {
+				continue
+			}
+			covering := (51 - i) / 8 // cards dealt on top in the same cascade.
+			weight := int(THRE + 1 - c.Rank)
+			buried += covering * weight
+			worst += 6 * weight // buried under a full cascade.
+		}
+		rating = buried * 100 / worst
+	}
+	l.ratings[seed] = rating
+	return rating
+}
+
+// FindSeedByDifficulty returns a random solvable seed whose deal rating is
+// in the given band. Returns false if no seed was found within the search
+// time budget.
+func (l *logic) FindSeedByDifficulty(band DifficultyBand) (seed uint, ok bool) {
+	start := time.Now()
+	for time.Since(start) < seedSearchBudget {
+		seed = uint(rand.Intn(int(MAX_SEED) + 1))
+		rating := l.DealRating(seed)
+		if rating >= band.Min && rating < band.Max && l.IsGameSolvable(seed) {
+			return seed, true
+		}
+	}
+	return 0, false
+}
+
 // IsGameWon returns true when all the kings are on the foundation piles.
 func (l *logic) IsGameWon() bool {
 	return l.board[KC] == FC && l.board[KD] == FD &&
 		l.board[KH] == FH && l.board[KS] == FS
 }
 
+// FoundationComplete returns true when the foundation for the given suit
+// has been built up to the king.
+func (l *logic) FoundationComplete(suit uint) bool {
+	if suit > SPD {
+		return false
+	}
+	return getCard(l.cardAt(suit+FC)).Rank == KING
+}
+
+// CardsAbove returns the cards stacked on top of the given card in its
+// cascade, nearest first. Returns nil for cards that are not in a cascade.
+func (l *logic) CardsAbove(cid uint) (above []uint) {
+	if !l.isCascade(l.board[cid]) {
+		return nil
+	}
+	for next := l.cardAt(l.board[cid] + 8); next != NO_CARD; next = l.cardAt(l.board[next] + 8) {
+		above = append(above, next)
+	}
+	return above
+}
+
+// AceBlockers returns the aces that are not yet on the foundations and
+// the cascade cards stacked on them, ie: the cards that must be moved
+// to free the aces.
+func (l *logic) AceBlockers() (aces, blockers []uint) {
+	for ace := AC; ace <= AS; ace++ {
+		if bid := l.board[ace]; l.isCascade(bid) || l.isFreecell(bid) {
+			aces = append(aces, ace)
+			blockers = append(blockers, l.CardsAbove(ace)...)
+		}
+	}
+	return aces, blockers
+}
+
+// Foundations returns the card IDs on each suit foundation, in clubs,
+// diamonds, hearts, spades order. Each stack is ordered from the ace up
+// to the top card. The buried foundation cards are only visible in the
+// board as HIDDEN_CARD locations, so this is the way to read them.
+func (l *logic) Foundations() (stacks [4][]uint) {
+	for suit := CLB; suit <= SPD; suit++ {
+		pile := suit + FC
+		for rank := ACES; rank <= KING; rank++ {
+			cid := rank*4 + suit // deck order is by rank then suit.
+			if bid := l.board[cid]; bid != pile && bid != pile+HIDDEN_CARD {
+				break
+			}
+			stacks[suit] = append(stacks[suit], cid)
+		}
+	}
+	return stacks
+}
+
 // Return the current number of moves. This is like keeping score.
 // It is calculated as the number of available undos plus 2 times
 // the number of undos that have been done (since each undo reduces
@@ -255,6 +469,12 @@ func (l *logic) MoveCount() int {
 	return 0
 }
 
+// MoveNumber returns the number of moves that have been played to reach
+// the current board position. Unlike MoveCount, undone moves are not counted.
+func (l *logic) MoveNumber() int {
+	return max(0, len(l.moves.stack)-1)
+}
+
 // GetSelected returns the selected card and its cascade sequence.
 // An empty vector is returned if nothing is selected.
 // If selected is valid, and there is a sequence, then the sequence
@@ -265,6 +485,9 @@ func (l *logic) GetSelected() (v []uint) {
 		return v
 	}
 	v = append(v, uint(l.selected)) // return at least the selected card.
+	if l.singleCardMoves {
+		return v // sequences are not allowed.
+	}
 
 	// return the selected card and its cascade sequence if one is available.
 	maxCascade := 10     // prevent infinite loops if state is bad.
@@ -282,30 +505,229 @@ func (l *logic) GetSelected() (v []uint) {
 	return v
 }
 
-// Undo the most recent move.
+// Undo the most recent move, returning the board before the undo.
 // Triggered the UI due to user action.
-func (l *logic) Undo() {
+// The selected card is kept if enabled and it can still be selected.
+func (l *logic) Undo() (before [52]uint) {
+	before = l.board
+	previous := l.selected
 	l.clearSelected()        // clear any picked cards
 	l.board = l.moves.undo() // reset the board to the previous game state.
+	if l.undoKeepsSelection && l.canSelectCard(previous) {
+		l.selected = previous
+	}
+	return before
+}
+
+// Timeline returns the time of each played move since the deal.
+// Expected to be exported to verify speedrun times.
+// Returns nil if no game has been dealt.
+func (l *logic) Timeline() (times []time.Duration) {
+	if l.moves == nil || len(l.moves.times) == 0 {
+		return nil
+	}
+	for _, t := range l.moves.times[1:] {
+		times = append(times, t.Sub(l.moves.times[0]))
+	}
+	return times
+}
+
+// Bookmark returns the current game position along with the moves
+// played to reach it.
+func (l *logic) Bookmark() Bookmark {
+	return Bookmark{Seed: l.gameSeed, History: l.History(), Undos: l.moves.undos}
+}
+
+// RestoreBookmark starts the bookmarked game at the bookmarked position.
+// The moves to reach the position are kept so that they can be undone,
+// along with the undo count for the move count score. Auto moves wait
+// for the next player move. Returns an error if a bookmarked move can't
+// be played, or an older bookmark doesn't start with the deal for its
+// game or contains an invalid board.
+func (l *logic) RestoreBookmark(b Bookmark) error {
+	if len(b.Moves) == 0 {
+		if err := l.LoadFromHistory(b.Seed, b.History); err != nil {
+			return fmt.Errorf("bookmark: %w", err)
+		}
+		l.moves.undos = max(b.Undos, 0)
+		l.playerMoved = false
+		return nil
+	}
+
+	// older bookmarks kept the board after each move.
+	check := &logic{}
+	check.NewGame(b.Seed)
+	if len(b.Moves) == 0 || b.Moves[0] != check.board {
+		return fmt.Errorf("bookmark does not start with the deal for game %d", b.Seed)
+	}
+	for i, board := range b.Moves {
+		check.board = board
+		if err := check.ValidateBoard(); err != nil {
+			return fmt.Errorf("bookmark move %d: %w", i, err)
+		}
+	}
+	l.NewGame(b.Seed)
+	for _, board := range b.Moves[1:] {
+		l.moves.record(board)
+	}
+	l.board = b.Moves[len(b.Moves)-1]
+	l.moves.undos = max(b.Undos, 0)
+	return nil
+}
+
+// History returns the moves played since the deal, including auto moves,
+// as {pick, place} pairs that can be passed to Interact. The game can
+// be shared and reloaded with LoadFromHistory.
+func (l *logic) History() (history [][2]uint) {
+	for i := 1; i < len(l.moves.stack); i++ {
+		if mv, ok := historyMove(l.moves.stack[i-1], l.moves.stack[i]); ok {
+			history = append(history, mv)
+		}
+	}
+	return history
+}
+
+// historyMove returns the {pick, place} pair that changed the from board
+// position into the to board position. Returns false if no cards moved.
+func historyMove(from, to [52]uint) (mv [2]uint, ok bool) {
+	lead := NO_CARD
+	for cid := AC; cid <= KS; cid++ {
+		if from[cid] == to[cid] || to[cid] >= HIDDEN_CARD {
+			continue // ignore unmoved and buried foundation cards.
+		}
+		if lead == NO_CARD || to[cid] < to[lead] {
+			lead = cid // top card of a moved sequence.
+		}
+	}
+	if lead == NO_CARD {
+		return mv, false
+	}
+
+	// place on the card below the lead card, or on the foundation top
+	// card, otherwise on the empty pile.
+	dest := to[lead]
+	for cid := AC; cid <= KS; cid++ {
+		switch {
+		case dest > 15 && to[cid] == dest-8:
+			return [2]uint{lead, cid}, true
+		case dest >= FC && dest <= FS && from[cid] == dest:
+			return [2]uint{lead, cid}, true
+		}
+	}
+	return [2]uint{lead, EMPTY_PILE1 + dest}, true
+}
+
+// LoadFromHistory deals the given game and plays the given moves, keeping
+// them so that they can be undone. Returns an error identifying the first
+// move that can't be played, in which case the current game is unchanged.
+func (l *logic) LoadFromHistory(seed uint, history [][2]uint) error {
+	check := &logic{rules: l.rules, singleCardMoves: l.singleCardMoves, closedCells: l.closedCells}
+	check.NewGame(seed)
+	for i, mv := range history {
+		if !check.CanMove(mv[0]) {
+			err := &PlacementError{Pick: mv[0], Selected: NO_CARD, Err: ErrNotSelectable}
+			return fmt.Errorf("history move %d: %w", i+1, err)
+		}
+		check.selected = mv[0]
+		if !check.canMoveTo(mv[0], mv[1]) || !check.Interact(mv[1]) {
+			err := &PlacementError{Pick: mv[1], Selected: mv[0], Err: ErrNotPlaceable}
+			return fmt.Errorf("history move %d: %w", i+1, err)
+		}
+	}
+	l.NewGame(seed)
+	for _, board := range check.moves.stack[1:] {
+		l.moves.record(board)
+	}
+	l.board = check.board
+	l.playerMoved = check.playerMoved // allow auto moves to continue.
+	l.clearSelected()
+	return nil
+}
+
+// playerMove records a card move made by the player.
+// Returns true if the board changed.
+func (l *logic) playerMove() bool {
+	moved := l.moves.record(l.board)
+	l.playerMoved = l.playerMoved || moved
+	return moved
 }
 
 // Board returns the board positions for each card.
 func (l *logic) Board() [52]uint { return l.board }
 
 // PreviousBoard returns the previous board positions for each card.
+// The deal is returned until a move has been made, and the current
+// board is returned if no game has been dealt.
 func (l *logic) PreviousBoard() [52]uint {
 	mv := l.moves
+	if mv == nil || len(mv.stack) == 0 {
+		return l.board // no game yet.
+	}
 	if len(mv.stack) > 1 {
 		return mv.stack[len(mv.stack)-2] // previous board.
 	}
 	return mv.stack[len(mv.stack)-1] // current board
 }
 
+// describeMove returns a short description of the cards that moved between
+// two board positions, ie: "5H->6S". The destination is a card symbol for
+// cascade moves, "F" for foundations, "free" for freecells, and "empty"
+// for empty cascades. Differences that are not a single card or a single
+// cascade sequence are described by the number of cards moved.
+// Returns an empty string if no cards moved.
+func describeMove(from, to [52]uint) string {
+	lead, count := InvalidCard, 0
+	for cid := AC; cid <= KS; cid++ {
+		if from[cid] == to[cid] || to[cid] >= HIDDEN_CARD {
+			continue // ignore unmoved and buried foundation cards.
+		}
+		count++
+		if lead.ID == NO_CARD || to[cid] < to[lead.ID] {
+			lead = deck[cid] // top card of a moved sequence.
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+
+	// a valid sequence move stacks the moved cards directly below the lead card.
+	for cid := AC; cid <= KS; cid++ {
+		if from[cid] == to[cid] || to[cid] >= HIDDEN_CARD || cid == lead.ID {
+			continue
+		}
+		below := to[cid] - to[lead.ID]
+		if to[lead.ID] < 8 || below%8 != 0 || below/8 >= uint(count) {
+			return fmt.Sprintf("%d cards", count)
+		}
+	}
+
+	// describe the destination.
+	dest := to[lead.ID]
+	switch {
+	case dest <= 3:
+		return lead.Sym + "->free"
+	case dest <= 7:
+		return lead.Sym + "->F"
+	case dest <= 15:
+		return lead.Sym + "->empty"
+	}
+	for cid := AC; cid <= KS; cid++ {
+		if to[cid] == dest-8 {
+			return lead.Sym + "->" + deck[cid].Sym
+		}
+	}
+	return lead.Sym // not expected: cascade gap.
+}
+
 // Interact handles a user action, either picking a card or placing a card.
 // - pick: AC:KS for a card, EMPTY_PILE1:EMPTY_PILE16 for empty piles
+// Other picks are ignored, leaving the game and the selection unchanged.
 //
 // return true if one more cards was moved to a new location.
 func (l *logic) Interact(pick uint) bool {
+	if !isPick(pick) {
+		return false // not a card or empty pile, ie: from an external caller.
+	}
 	if !l.canInteract(pick) {
 		previousPick := l.selected
 		l.clearSelected() // clear picked card...
@@ -336,10 +758,9 @@ func (l *logic) Interact(pick uint) bool {
 			switch {
 			case l.isFreecell(pileID) && len(seq) == 1:
 				// place a single card in an empty freecell
-				if l.emptyPile(pileID) {
+				if l.isOpenFreecell(pileID) && l.emptyPile(pileID) {
 					l.board[s.ID] = pileID
-					l.moves.record(l.board)
-					return true
+					return l.playerMove()
 				}
 
 			case l.isFoundation(pileID) && len(seq) == 1:
@@ -349,8 +770,7 @@ func (l *logic) Interact(pick uint) bool {
 					// of the suit for that foundation pile.
 					if l.emptyPile(pileID) && s.Rank == ACES {
 						l.board[s.ID] = pileID
-						l.moves.record(l.board)
-						return true
+						return l.playerMove()
 					}
 				}
 
@@ -367,8 +787,7 @@ func (l *logic) Interact(pick uint) bool {
 					for i := 1; i < len(seq); i++ {
 						l.board[seq[i]] = l.board[seq[i-1]] + 8
 					}
-					l.moves.record(l.board)
-					return true
+					return l.playerMove()
 				}
 			}
 
@@ -387,8 +806,7 @@ func (l *logic) Interact(pick uint) bool {
 					// selected card is the new foundation top.
 					l.board[p.ID] = l.board[p.ID] + HIDDEN_CARD
 					l.board[s.ID] = boardPick
-					l.moves.record(l.board)
-					return true
+					return l.playerMove()
 				}
 
 			case l.isCascade(boardPick):
@@ -401,8 +819,7 @@ func (l *logic) Interact(pick uint) bool {
 					for i := 1; i < len(seq); i++ {
 						l.board[seq[i]] = l.board[seq[i-1]] + 8
 					}
-					l.moves.record(l.board)
-					return true
+					return l.playerMove()
 				}
 			}
 		}
@@ -417,6 +834,61 @@ func (l *logic) Interact(pick uint) bool {
 	return false // no card was moved.
 }
 
+// Reasons for a PlacementError.
+var (
+	ErrNotSelectable = errors.New("can't be selected")
+	ErrNotPlaceable  = errors.New("selection can't be placed there")
+	ErrInvalidPick   = errors.New("not a card or empty pile")
+)
+
+// PlacementError explains why a pick did not select or move cards.
+type PlacementError struct {
+	Pick     uint  // AC:KS for a card, EMPTY_PILE1:EMPTY_PILE16 for empty piles.
+	Selected uint  // selected card at the time of the pick, or NO_CARD.
+	Err      error // ErrNotSelectable, ErrNotPlaceable, or ErrInvalidPick.
+}
+
+// Error implements the error interface.
+func (e *PlacementError) Error() string {
+	pick := getCard(e.Pick).Sym
+	switch {
+	case e.Pick >= EMPTY_PILE1 && e.Pick <= EMPTY_PILE16:
+		pick = fmt.Sprintf("pile %d", e.Pick-EMPTY_PILE1)
+	case !isPick(e.Pick):
+		pick = fmt.Sprintf("pick %d", e.Pick)
+	}
+	if isCard(e.Selected) {
+		return fmt.Sprintf("%s on %s: %s", getCard(e.Selected).Sym, pick, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", pick, e.Err)
+}
+
+// Unwrap returns the reason for the error.
+func (e *PlacementError) Unwrap() error { return e.Err }
+
+// InteractErr is Interact with an error explaining an illegal pick or
+// placement. Selecting a card, changing the selection, or deselecting
+// the selected card are not errors.
+func (l *logic) InteractErr(pick uint) (moved bool, err error) {
+	selected, reason := l.selected, ErrNotSelectable
+	switch {
+	case !isPick(pick):
+		reason = ErrInvalidPick
+	case l.isSelectionActive():
+		reason = ErrNotPlaceable
+	}
+	moved = l.Interact(pick)
+	switch {
+	case moved:
+		return true, nil
+	case l.isSelectionActive() && l.selected == pick:
+		return false, nil // selected a card.
+	case isCard(selected) && pick == selected:
+		return false, nil // deselected the card.
+	}
+	return false, &PlacementError{Pick: pick, Selected: selected, Err: reason}
+}
+
 // Trys to move cards safely to the foundation.
 // Returns true if one or more cards were moved.
 // check if a card should be moved to the foundation.
@@ -428,115 +900,396 @@ func (l *logic) Interact(pick uint) bool {
 func (l *logic) AutoMoveCard() bool {
 
 	// ignore auto moves until player has made the first move.
-	if l.moves.count() < 2 {
+	if !l.playerMoved {
 		return false
 	}
 
+	// check the free cell cards and the last card of each cascade.
+	for _, c := range l.exposedCards() {
+		if !l.isSafeAutoMove(c) {
+			continue
+		}
+
+		// hide the current top foundation card, and
+		// move the candidate to the foundation.
+		pile := c.Suit + FC
+		if top := l.cardAt(pile); top != NO_CARD {
+			l.board[top] = pile + HIDDEN_CARD
+		}
+		l.board[c.ID] = pile
+		l.moves.record(l.board)
+		if l.isSelected(c.ID) {
+			l.clearSelected()
+		}
+		return true
+	}
+	return false // no cards moved
+}
+
+// exposedCards returns the cards that can be picked up: the free cell
+// cards and then the last card of each cascade.
+func (l *logic) exposedCards() (cards []Card) {
+	for bid := uint(0); bid < 4; bid++ {
+		if cid := l.cardAt(bid); cid != NO_CARD {
+			cards = append(cards, getCard(cid))
+		}
+	}
+	for cascade := uint(0); cascade < 8; cascade++ {
+		if c := l.lastInCascade(cascade); c.ID != NO_CARD {
+			cards = append(cards, c)
+		}
+	}
+	return cards
+}
+
+// isSafeAutoMove returns true if the card is next for its foundation
+// and can't be needed to build the cascades. A card is safe when it is
+// at most two ranks above the lowest foundation, so that all the cards
+// it could hold are already up.
+func (l *logic) isSafeAutoMove(c Card) bool {
 	// get the current top foundation cards. They may be empty.
-	fc := getCard(l.cardAt(FC))
-	fd := getCard(l.cardAt(FD))
-	fh := getCard(l.cardAt(FH))
-	fs := getCard(l.cardAt(FS))
+	var tops [4]Card
+	for suit := CLB; suit <= SPD; suit++ {
+		tops[suit] = getCard(l.cardAt(suit + FC))
+	}
 	minRank := -1 // meaning one of the foundations is empty
-	if fc.ID != NO_CARD && fd.ID != NO_CARD &&
-		fh.ID != NO_CARD && fs.ID != NO_CARD {
-		minRank = min(int(fc.Rank), int(fd.Rank), int(fh.Rank), int(fs.Rank))
-	}
-
-	// all selectable cards are candidates, some of these may be empty.
-	candidates := []Card{
-		getCard(l.cardAt(0)), // freecell cards
-		getCard(l.cardAt(1)),
-		getCard(l.cardAt(2)),
-		getCard(l.cardAt(3)),
-		l.lastInCascade(0), // cascade cards
-		l.lastInCascade(1),
-		l.lastInCascade(2),
-		l.lastInCascade(3),
-		l.lastInCascade(4),
-		l.lastInCascade(5),
-		l.lastInCascade(6),
-		l.lastInCascade(7),
-	}
-
-	// check the 12 candidate cards
-	// "hide" buried foundation cards.
-	for _, c := range candidates {
-		if c.ID == NO_CARD {
-			continue // ignore empty piles
-		}
-
-		// can only move up if all of the previous ranks are up.
-		if int(c.Rank) != minRank+1 && int(c.Rank) != minRank+2 {
-			continue // ignore cards that can't move up.
-		}
-
-		// check if the card is next in the foundation.
-		boardID := c.Suit + 4
-		switch c.Suit {
-		case CLB:
-			if l.isNextInFoundation(c.Suit, fc, c) {
-				if fc.ID != NO_CARD {
-					// hide current top foundation card.
-					l.board[fc.ID] = l.board[fc.ID] + HIDDEN_CARD
-				}
+	if tops[CLB].ID != NO_CARD && tops[DMD].ID != NO_CARD &&
+		tops[HRT].ID != NO_CARD && tops[SPD].ID != NO_CARD {
+		minRank = min(int(tops[CLB].Rank), int(tops[DMD].Rank), int(tops[HRT].Rank), int(tops[SPD].Rank))
+	}
+	if int(c.Rank) != minRank+1 && int(c.Rank) != minRank+2 {
+		return false // can't move up until the previous ranks are up.
+	}
+	return l.isNextInFoundation(c.Suit, tops[c.Suit], c)
+}
 
-				// move the candidate to the foundation.
-				l.board[c.ID] = boardID
-				l.moves.record(l.board)
-				if l.isSelected(c.ID) {
-					l.clearSelected()
-				}
-				return true
+// safeCards returns the exposed cards that are safe to auto move.
+func (l *logic) safeCards() (safe []uint) {
+	for _, c := range l.exposedCards() {
+		if l.isSafeAutoMove(c) {
+			safe = append(safe, c.ID)
+		}
+	}
+	return safe
+}
+
+// newlySafeCards returns the cards that are safe to auto move on the
+// after board that weren't on the before board, ie: cards exposed or
+// made safe by a move.
+func newlySafeCards(before, after [52]uint) (cards []uint) {
+	was, now := &logic{board: before}, &logic{board: after}
+	for _, cid := range now.safeCards() {
+		if !slices.Contains(was.safeCards(), cid) {
+			cards = append(cards, cid)
+		}
+	}
+	return cards
+}
+
+// CanAutoComplete returns true if the game is certain to be won by moving
+// the remaining cards to the foundations in rank order. This is the case
+// when each cascade is in descending rank order, since then the lowest
+// ranked card is always a free cell card or the last card in a cascade.
+func (l *logic) CanAutoComplete() bool {
+	if l.IsGameWon() {
+		return false
+	}
+	for cid := AC; cid <= KS; cid++ {
+		bid := l.board[cid]
+		if !l.isCascade(bid) {
+			continue
+		}
+		if below := l.cardAt(bid + 8); below != NO_CARD && getCard(below).Rank > getCard(cid).Rank {
+			return false
+		}
+	}
+	return true
+}
+
+// AutoComplete moves all the remaining cards to the foundations, lowest
+// rank first, when the game can be auto completed. Each card is recorded
+// as a move so that the score matches finishing the game by hand.
+// Returns false, without moving any cards, if the game can't be auto
+// completed.
+func (l *logic) AutoComplete() bool {
+	if !l.CanAutoComplete() {
+		return false
+	}
+	l.clearSelected()
+	for !l.IsGameWon() {
+		next := InvalidCard // lowest ranked available card.
+		for cid := AC; cid <= KS; cid++ {
+			bid, c := l.board[cid], deck[cid]
+			free := l.isFreecell(bid) || (l.isCascade(bid) && l.cardAt(bid+8) == NO_CARD)
+			if free && (next.ID == NO_CARD || c.Rank < next.Rank) {
+				next = c
 			}
-		case DMD:
-			if l.isNextInFoundation(c.Suit, fd, c) {
-				if fd.ID != NO_CARD {
-					// hide current top foundation card.
-					l.board[fd.ID] = l.board[fd.ID] + HIDDEN_CARD
-				}
+		}
+		if next.ID == NO_CARD {
+			slog.Error("auto complete has no available cards")
+			return false
+		}
 
-				// move the candidate to the foundation.
-				l.board[c.ID] = boardID
-				l.moves.record(l.board)
-				if l.isSelected(c.ID) {
-					l.clearSelected()
-				}
-				return true
+		// hide the current top foundation card.
+		pile := next.Suit + FC
+		if top := l.cardAt(pile); top != NO_CARD {
+			l.board[top] = pile + HIDDEN_CARD
+		}
+		l.board[next.ID] = pile
+		l.moves.record(l.board)
+	}
+	return true
+}
+
+// SendSuitChain continues a foundation move by sending the next ranks
+// of the same suit to the foundation while they are available, ie: the
+// last card in a cascade or a free cell card. The chain starts from the
+// given card, which must be the top of its foundation. Each card is
+// recorded as a move. Returns the moved cards, stopping at the first
+// rank that isn't available.
+func (l *logic) SendSuitChain(cardID uint) (sent []uint) {
+	for next, ok := l.sendNextInSuit(cardID); ok; next, ok = l.sendNextInSuit(next) {
+		sent = append(sent, next)
+	}
+	return sent
+}
+
+// sendNextInSuit sends the next rank of the suit to the foundation if
+// the given card is the top of its foundation and the next rank is
+// available. Returns the moved card and true if a card moved.
+func (l *logic) sendNextInSuit(cardID uint) (next uint, ok bool) {
+	if !isCard(cardID) || deck[cardID].Rank == KING {
+		return NO_CARD, false
+	}
+	pile := deck[cardID].Suit + FC
+	next = cardID + 4 // deck order is by rank then suit.
+	bid := l.board[next]
+	available := l.isFreecell(bid) || (l.isCascade(bid) && l.cardAt(bid+8) == NO_CARD)
+	if l.board[cardID] != pile || !available {
+		return NO_CARD, false
+	}
+	l.board[cardID] = pile + HIDDEN_CARD
+	l.board[next] = pile
+	l.moves.record(l.board)
+	if l.isSelected(next) {
+		l.clearSelected()
+	}
+	return next, true
+}
+
+// foundationMove returns the card that was moved to a foundation between
+// the before and after boards, ie: a foundation move that can be
+// continued with SendSuitChain. Returns false if no card was moved to a
+// foundation.
+func foundationMove(before, after [52]uint) (cardID uint, ok bool) {
+	for cid := AC; cid <= KS; cid++ {
+		if bid := after[cid]; bid != before[cid] && bid >= FC && bid <= FS {
+			return cid, true
+		}
+	}
+	return NO_CARD, false
+}
+
+// AutoMoveAll moves all the safe cards to the foundation.
+// Returns the number of cards that were moved.
+func (l *logic) AutoMoveAll() (moved int) {
+	for l.AutoMoveCard() {
+		moved++
+	}
+	return moved
+}
+
+// LegalMoves returns the moves available on the current board as
+// {pick, place} pairs that can be passed to Interact.
+func (l *logic) LegalMoves() (legal [][2]uint) {
+	for pick := AC; pick <= KS; pick++ {
+		if !l.CanMove(pick) {
+			continue
+		}
+		for place := AC; place <= KS; place++ {
+			if l.canMoveTo(pick, place) {
+				legal = append(legal, [2]uint{pick, place})
 			}
-		case HRT:
-			if l.isNextInFoundation(c.Suit, fh, c) {
-				if fh.ID != NO_CARD {
-					// hide current top foundation card.
-					l.board[fh.ID] = l.board[fh.ID] + HIDDEN_CARD
-				}
+		}
+		for place := EMPTY_PILE1; place <= EMPTY_PILE16; place++ {
+			if l.canMoveTo(pick, place) {
+				legal = append(legal, [2]uint{pick, place})
+			}
+		}
+	}
+	return legal
+}
 
-				// move the candidate to the foundation.
-				l.board[c.ID] = boardID
-				l.moves.record(l.board)
-				if l.isSelected(c.ID) {
-					l.clearSelected()
-				}
+// MovesExist returns true if any card can be moved. The board is checked
+// directly, independent of the card selection rules, so that it can be
+// used to verify them. Only single cards need to be checked: without an
+// empty free cell or cascade a sequence can't be moved, and with one
+// any cascade or free cell card can be moved.
+func (l *logic) MovesExist() bool {
+	movable := []Card{} // cascade tails and free cell cards.
+	tails := []Card{}   // cascade tails.
+	for cid := AC; cid <= KS; cid++ {
+		switch bid := l.board[cid]; {
+		case l.isFreecell(bid):
+			movable = append(movable, deck[cid])
+		case l.isCascade(bid) && l.cardAt(bid+8) == NO_CARD:
+			movable = append(movable, deck[cid])
+			tails = append(tails, deck[cid])
+		}
+	}
+	if len(movable) == 0 {
+		return false // all cards are on the foundations.
+	}
+	if l.emptyFreeCells() > 0 || l.emptyCascades() > 0 {
+		return true
+	}
+	for _, c := range movable {
+		if l.isNextInFoundation(c.Suit, getCard(l.cardAt(c.Suit+FC)), c) {
+			return true
+		}
+		for _, tail := range tails {
+			if l.nextInSequence(tail, c) {
 				return true
 			}
-		case SPD:
-			if l.isNextInFoundation(c.Suit, fs, c) {
-				if fs.ID != NO_CARD {
-					// hide current top foundation card.
-					l.board[fs.ID] = l.board[fs.ID] + HIDDEN_CARD
-				}
+		}
+	}
+	return false
+}
 
-				// move the candidate to the foundation.
-				l.board[c.ID] = boardID
-				l.moves.record(l.board)
-				if l.isSelected(c.ID) {
-					l.clearSelected()
-				}
-				return true
+// Hint suggests a move as a {pick, place} pair that can be passed to
+// Interact. Moves to the foundations are preferred, then building on the
+// cascades. When the free cells are full and there are no empty cascades
+// a move that frees one is suggested first. Returns false if there are
+// no moves.
+func (l *logic) Hint() (move [2]uint, ok bool) {
+	if l.emptyFreeCells() == 0 && l.emptyCascades() == 0 {
+		if move, ok = l.SuggestUnblock(); ok {
+			return move, true
+		}
+	}
+	best := -1
+	for _, mv := range l.LegalMoves() {
+		rank := 0 // free cells and empty cascades.
+		switch place := mv[1]; {
+		case isCard(place) && l.isFoundation(l.board[place]):
+			rank = 2
+		case place >= EMPTY_PILE1 && l.isFoundation(place-EMPTY_PILE1):
+			rank = 2
+		case isCard(place):
+			rank = 1 // onto a cascade card.
+		}
+		if rank > best {
+			move, best = mv, rank
+		}
+	}
+	return move, best >= 0
+}
+
+// SuggestUnblock suggests a move that frees a free cell or empties a
+// cascade, for when there is no room left to work with. Moving a free
+// cell card to the foundations is preferred, then onto a cascade, then
+// moving a whole cascade. Returns false if no move frees anything, ie:
+// the game is deadlocked when the free cells are full.
+func (l *logic) SuggestUnblock() (move [2]uint, ok bool) {
+	best := -1
+	for _, mv := range l.LegalMoves() {
+		pick, place := mv[0], mv[1]
+		rank := -1
+		switch {
+		case l.isFreecell(l.board[pick]) && isCard(place) && l.isFoundation(l.board[place]):
+			rank = 3
+		case l.isFreecell(l.board[pick]) && place >= EMPTY_PILE1 && l.isFoundation(place-EMPTY_PILE1):
+			rank = 3
+		case l.isFreecell(l.board[pick]) && isCard(place):
+			rank = 2 // onto a cascade card.
+		case l.isCascade(l.board[pick]) && l.board[pick] < 16 && (isCard(place) || l.isFoundation(place-EMPTY_PILE1)):
+			rank = 1 // the whole cascade moves.
+		}
+		if rank > best {
+			move, best = mv, rank
+		}
+	}
+	return move, best >= 0
+}
+
+// CanMove returns true if the given card can be picked up and moved
+// somewhere, ie: to grey out cards that can't move. Foundation cards
+// can never be moved. The selection is not changed.
+func (l *logic) CanMove(cardID uint) bool {
+	return l.canSelectCard(cardID)
+}
+
+// CanMoveTo returns true if the given card, along with the cards in
+// sequence on top of it, can be placed on the picked card or empty pile.
+// - pick: AC:KS for a card, EMPTY_PILE1:EMPTY_PILE16 for empty piles
+//
+// The selection is not changed.
+func (l *logic) CanMoveTo(cardID, pick uint) bool {
+	return l.canSelectCard(cardID) && l.canMoveTo(cardID, pick)
+}
+
+// canMoveTo is CanMoveTo for a card that is known to be movable.
+func (l *logic) canMoveTo(cardID, pick uint) bool {
+	isPile := pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16
+	if !isCard(pick) && !isPile {
+		return false
+	}
+	selected := l.selected
+	defer func() { l.selected = selected }()
+	l.selected = cardID
+	if l.isSelected(pick) {
+		return false // can't move cards onto themselves.
+	}
+	if isPile && l.isCascade(pick-EMPTY_PILE1) && len(l.GetSelected()) > l.movableStackSize(true) {
+		return false // Interact aborts sequences too long for an empty cascade.
+	}
+	if isPile && !l.isCascade(pick-EMPTY_PILE1) && len(l.GetSelected()) > 1 {
+		return false // sequences only move to cascades.
+	}
+	return l.canPlaceCard(pick)
+}
+
+// ValidateBoard returns an error if the board is not a possible game
+// position: each card must be in a valid location without sharing it,
+// the cascades must not have gaps, and the foundations must be built
+// up in suit from the ace.
+func (l *logic) ValidateBoard() error {
+	visible := map[uint]uint{} // board location to card.
+	buried := [4]int{}         // hidden foundation cards for each suit.
+	for cid := AC; cid <= KS; cid++ {
+		bid, c := l.board[cid], getCard(cid)
+		switch {
+		case bid >= FC+HIDDEN_CARD && bid <= FS+HIDDEN_CARD:
+			pile := bid - HIDDEN_CARD
+			if c.Suit != pile-FC || c.Rank >= getCard(l.cardAt(pile)).Rank {
+				return fmt.Errorf("card %s buried out of order on foundation %d", c.Sym, pile)
 			}
+			buried[c.Suit]++
+			continue
+		case bid > MAX_BOARD_ID:
+			return fmt.Errorf("card %s has invalid location %d", c.Sym, bid)
+		case l.isFoundation(bid) && c.Suit != bid-FC:
+			return fmt.Errorf("card %s on the wrong foundation %d", c.Sym, bid)
+		case l.isCascade(bid) && bid >= 16 && l.cardAt(bid-8) == NO_CARD:
+			return fmt.Errorf("card %s has a gap above it in the cascade", c.Sym)
+		}
+		if other, ok := visible[bid]; ok {
+			return fmt.Errorf("cards %s and %s share location %d", c.Sym, getCard(other).Sym, bid)
 		}
+		visible[bid] = cid
 	}
-	return false // no cards moved
+
+	// each foundation top card has all the lower ranks buried beneath it.
+	for suit := CLB; suit <= SPD; suit++ {
+		top, ok := visible[suit+FC]
+		switch {
+		case !ok && buried[suit] > 0:
+			return fmt.Errorf("foundation %d has buried cards without a top card", suit+FC)
+		case ok && int(getCard(top).Rank) != buried[suit]:
+			return fmt.Errorf("foundation %d top card %s is missing lower cards", suit+FC, getCard(top).Sym)
+		}
+	}
+	return nil
 }
 
 // get the card at the given board location.
@@ -596,10 +1349,10 @@ func (l *logic) emptyPile(pileID uint) bool {
 	return false
 }
 
-// emptyFreeCells returns the number of empty free cells.
+// emptyFreeCells returns the number of empty free cells that can be used.
 func (l *logic) emptyFreeCells() int {
 	piles := []uint{0, 1, 2, 3}
-	return l.countEmptyCells(piles)
+	return l.countEmptyCells(piles[:4-l.closedCells])
 }
 
 // emptyCascades returns the number of empty cascade piles
@@ -619,28 +1372,32 @@ func (l *logic) countEmptyCells(piles []uint) int {
 	return empty
 }
 
-// nextInSequence returns true if a can be placed on b in cascade,
-// ie: returns true if Card b is 1 rank less than card a and is the opposite suit.
-func (l *logic) nextInSequence(a, b Card) bool {
-	return (b.Rank == (a.Rank - 1)) && b.Color != a.Color
+// ruleSet returns the rules of play.
+func (l *logic) ruleSet() Rules {
+	if l.rules == nil {
+		return FreecellRules
+	}
+	return l.rules
 }
 
+// nextInSequence returns true if b can be placed on a in a cascade.
+func (l *logic) nextInSequence(a, b Card) bool { return l.ruleSet().InSequence(a, b) }
+
 // Card and Board position validation utilities.
 func (l *logic) isCard(cardID uint) bool        { return cardID >= AC && cardID <= KS }
 func (l *logic) isCascade(boardID uint) bool    { return boardID >= 8 && boardID <= MAX_BOARD_ID }
 func (l *logic) isFoundation(boardID uint) bool { return boardID >= 4 && boardID <= 7 }
 func (l *logic) isFreecell(boardID uint) bool   { return boardID >= 0 && boardID <= 3 }
 
-// isNextInFoundation returns true if Card b is the next
-// card that should be placed in the foundation pile for the given suit.
+// isOpenFreecell returns true for the free cells that can be used.
+func (l *logic) isOpenFreecell(boardID uint) bool {
+	return l.isFreecell(boardID) && boardID < uint(4-l.closedCells)
+}
+
+// isNextInFoundation returns true if Card b is the next card that
+// should be placed in the foundation pile for the given suit.
 func (l *logic) isNextInFoundation(suit uint, a, b Card) bool {
-	if suit > SPD {
-		slog.Error("isNextInFoundation invalid suit")
-		return false
-	}
-	onEmpty := a.ID == NO_CARD && b.Suit == suit && b.Rank == ACES
-	onCard := a.ID != NO_CARD && b.Suit == suit && b.Rank == a.Rank+1
-	return onEmpty || onCard
+	return l.ruleSet().NextInFoundation(suit, a, b)
 }
 
 // getSequence attempts to return a valid cascade sequence for the given cardID.
@@ -704,9 +1461,13 @@ func (l *logic) canMoveToCascade(cardID uint) bool {
 //
 // Currently choosing the more conservative max 1 empty cascade movable
 // stack size rather than the pow(2, emptyCascadeCount)
+// Always 1 when single card moves are enforced.
 // The formula has to adapt if the stack is being moved onto another non-empty cascade
 // or if it is being moved to an empty cascade, reducing the movable stack size.
 func (l *logic) movableStackSize(isEmptyCascadeUsed bool) int {
+	if l.singleCardMoves {
+		return 1
+	}
 	emptyCascades := l.emptyCascades()
 	if emptyCascades <= 0 {
 		return l.emptyFreeCells() + 1
@@ -721,6 +1482,42 @@ func (l *logic) movableStackSize(isEmptyCascadeUsed bool) int {
 	return l.emptyFreeCells() + 1
 }
 
+// supermoveCells returns the number of free cells and empty cascades
+// used to temporarily hold cards while moving a sequence of the given
+// size. Free cells are used first, then each empty cascade holds as many
+// cards as can be moved using the free cells. The destination cascade
+// can't hold cards if the sequence is moving to an empty cascade.
+func supermoveCells(size, freeCells, emptyCascades int, toEmptyCascade bool) (cells, cascades int) {
+	if size <= 1 {
+		return 0, 0 // single cards move directly.
+	}
+	if toEmptyCascade {
+		emptyCascades -= 1
+	}
+	cells = min(size-1, freeCells)
+	remaining := size - 1 - cells
+	cascades = min((remaining+freeCells)/(freeCells+1), max(0, emptyCascades))
+	return cells, cascades
+}
+
+// reservedFreecells returns the empty free cells that are needed
+// to move the selected sequence. Returns nothing if there is no
+// sequence selected.
+func (l *logic) reservedFreecells() (piles []uint) {
+	seq := l.GetSelected()
+	if len(seq) <= 1 {
+		return piles
+	}
+	toEmptyCascade := !l.canMoveToCascade(seq[0])
+	cells, _ := supermoveCells(len(seq), l.emptyFreeCells(), l.emptyCascades(), toEmptyCascade)
+	for pileID := uint(0); pileID < 4 && len(piles) < cells; pileID++ {
+		if l.isOpenFreecell(pileID) && l.emptyPile(pileID) {
+			piles = append(piles, pileID)
+		}
+	}
+	return piles
+}
+
 // isSelected returns true if the indicated card has been selected
 // for a move. This can include the cards in a cascade sequence.
 // Expected to be used by the UI to highlight selected cards.
@@ -750,137 +1547,47 @@ func (l *logic) canInteract(pick uint) bool {
 	return l.canSelectCard(pick)
 }
 
-// canPlaceCard returns true if the picked card can be placed
-// on another card or empty pile.
-func (l *logic) canPlaceCard(pick uint) bool {
-	selects := l.GetSelected()
-
-	// consider the empty piles
-	if pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16 {
-		s := getCard(selects[0])
-		pileID := pick - EMPTY_PILE1
-
-		// always valid to place a card on an empty freecell.
-		if l.isFreecell(pileID) && len(selects) == 1 {
-			return l.emptyPile(pileID)
-		}
-
-		// check placing a card on an empty foundation.
-		// The card must be an ACE matching the foundation suit.
-		if l.isFoundation(pileID) && len(selects) == 1 {
-			return (s.Suit == pileID-4) && s.Rank == ACES
-		}
-
-		// always valid to place a card on an empty cascade.
-		if pileID >= 8 && pileID <= 15 {
-			return l.emptyPile(pileID)
-		}
+// canPlaceCard returns true if the selected cards can be placed
+// on the picked card or empty pile.
+func (l *logic) canPlaceCard(pick uint) bool { return l.ruleSet().CanPlace(l, pick) }
 
-		// should not reach here.
-		slog.Error("invalid card pick", "pick", pick)
-		return false
+// dropValidity reports if the selected cards can be placed on the given
+// target card or empty pile. Nothing is shown when there is no selection,
+// no target, or when the target is one of the selected cards.
+func (l *logic) dropValidity(target uint) (show, valid bool) {
+	if !l.isSelectionActive() || target == NO_HIT || l.isSelected(target) {
+		return false, false
 	}
-
-	// the user picked a card in order to place the
-	// selected cards on the picked card.
-	cardID := uint(pick)
-	if l.isCard(cardID) {
-		p := getCard(cardID)
-		s := getCard(selects[0])
-		boardPick := l.board[cardID]
-
-		// if card is on a foundation pile, then it must be the next highest
-		// card rank and the same suit. Only valid for single selected card.
-		if l.isFoundation(boardPick) && len(selects) == 1 {
-			suit := boardPick - 4
-			return l.isNextInFoundation(suit, p, s)
-		}
-
-		// attempt to put the picked card onto the selected card.
-		// The pick card must be the last in the cascade and it must be
-		// the next highest rank and the opposite color from the top selected card.
-		if l.isCascade(boardPick) {
-			if l.isLastInCascade(cardID) {
-				return l.nextInSequence(p, s)
-			}
-			return false
-		}
-
-		// a picked card can't interact with cards in the freecells.
-		return false
-	}
-
-	// dev error: should never reach here
-	slog.Error("invalid canPlaceCard pick", "pick", pick)
-	return false
+	return true, l.canPlaceCard(target)
 }
 
-// canSelectCard returns true if the given board location has a selectable card.
-// Can only pick the cards, not the empty piles.
-// FUTURE: indicate when there are no available moves.
-func (l *logic) canSelectCard(pick uint) bool {
-	if !isCard(pick) {
-		return false
-	}
-	boardPick := l.board[pick] // board location of the picked card.
-
-	// foundation cards can never be picked up.
-	// FUTURE: make this an option. Some implementations allow cards to
-	//         be moved from the foundation back to the cascade.
-	if l.isFoundation(boardPick) {
-		return false
-	}
-
-	// check that the pick can be placed somewhere.
-	if l.isCascade(boardPick) || l.isFreecell(boardPick) {
-		seq := l.getSequence(pick)
-		if len(seq) <= 0 {
-			return false
-		}
-		c := getCard(seq[0]) // top card in picked sequence.
-
-		// check valid moves for single selections
-		if len(seq) == 1 {
-			if l.emptyFreeCells() > 0 {
-				return true // a single card can be moved to an empty cell.
-			}
-
-			// check if the card can be moved to a foundation pile.
-			foundationPileID := c.Suit + 4
-			if l.emptyPile(foundationPileID) && c.Rank == ACES {
-				return true
-			}
-			topCard := getCard(l.cardAt(foundationPileID))
-			if l.isNextInFoundation(c.Suit, topCard, c) {
-				return true
-			}
-		}
-		if l.emptyCascades() > 0 {
-			return true // a valid sequence can be moved to an empty cascade
-		}
-
-		// check the last card of each cascade to see if the first
-		// card in the sequence one can be placed on it.
-		return l.canMoveToCascade(seq[0])
-	}
-	return false
-}
+// canSelectCard returns true if the picked card can be selected.
+func (l *logic) canSelectCard(pick uint) bool { return l.ruleSet().CanSelect(l, pick) }
 
 // shuffle the deck based on the given seed.
+// This is the classic single deck deal used by all the games.
 func shuffle(seed uint, ordered [52]Card) (shuffled [52]Card) {
-	deck := [52]uint{} // deck of 52 unique cards
-	deal := [52]uint{} // ids of shuffled cards.
+	copy(shuffled[:], shuffleDeck(seed, ordered[:]))
+	return shuffled
+}
+
+// shuffleDeck shuffles a deck of any size based on the given seed.
+// The classic 52 card deal is reproduced for a standard deck. Larger
+// decks, ie: for a double deck variant, continue the same sequence.
+func shuffleDeck(seed uint, ordered []Card) (shuffled []Card) {
+	deck := make([]uint, len(ordered)) // deck of unique cards
+	deal := make([]uint, len(ordered)) // ids of shuffled cards.
 
 	// initialize the deck and deal.
-	for cid := AC; cid <= KS; cid++ {
-		deck[cid] = cid
+	for cid := range deck {
+		deck[cid] = uint(cid)
 		deal[cid] = NO_CARD
 	}
 
 	// shuffle
-	dealt := 0            // cards dealt.
-	remainder := uint(52) // remaining cards be dealt
-	srand(seed)           // seed the random number generator.
+	dealt := 0                      // cards dealt.
+	remainder := uint(len(ordered)) // remaining cards be dealt
+	srand(seed)                     // seed the random number generator.
 	for i := 0; i < len(deck); i++ {
 		j := randClassic() % remainder // choose a random card
 		deal[dealt] = deck[j]          // deal the random card
@@ -890,6 +1597,7 @@ func shuffle(seed uint, ordered [52]Card) (shuffled [52]Card) {
 	}
 
 	// create and return the shuffled deck of cards.
+	shuffled = make([]Card, len(deal))
 	for i := 0; i < len(deal); i++ {
 		shuffled[i] = ordered[deal[i]]
 	}
@@ -920,19 +1628,31 @@ func getCard(cardID uint) Card {
 // Return true if the card id is valid.
 func isCard(cardID uint) bool { return cardID >= AC && cardID <= KS }
 
+// Return true if the pick is a card or an empty pile.
+func isPick(pick uint) bool { return isCard(pick) || (pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16) }
+
 // -----------------------------------------------------------------------------
 // moves records player moves, allowing undos.
 // Records the board position of each card after each move.
 // FUTURE: support Redos.
 type moves struct {
-	stack [][52]uint // each move is the board position of each card.
-	undos int        // count number of player undos
+	stack [][52]uint  // each move is the board position of each card.
+	times []time.Time // when each move was made, parallel to stack.
+	undos int         // count number of player undos
 }
 
 // record the current board position.
 // Array's are passed by value, so this is copy.
-func (mv *moves) record(move [52]uint) {
+// A board identical to the last recorded board is not a move and is
+// skipped so that it doesn't inflate the move count or need an extra undo.
+// Returns true if the board was recorded.
+func (mv *moves) record(move [52]uint) bool {
+	if len(mv.stack) > 0 && mv.stack[len(mv.stack)-1] == move {
+		return false // no change.
+	}
 	mv.stack = append(mv.stack, move) // push
+	mv.times = append(mv.times, time.Now())
+	return true
 }
 
 // undo updates gamestate to the previous move.
@@ -940,6 +1660,7 @@ func (mv *moves) record(move [52]uint) {
 func (mv *moves) undo() (previousBoard [52]uint) {
 	if len(mv.stack) > 1 {
 		mv.stack = mv.stack[:len(mv.stack)-1] // pop
+		mv.times = mv.times[:len(mv.times)-1]
 		mv.undos += 1
 	}
 	return mv.stack[len(mv.stack)-1]
@@ -948,6 +1669,7 @@ func (mv *moves) undo() (previousBoard [52]uint) {
 // reset clears all moves and resets move counters
 func (mv *moves) reset() {
 	mv.stack = [][52]uint{}
+	mv.times = []time.Time{}
 	mv.undos = 0
 }
 
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"testing"
+)
+
+// Benchmarks for the logic hot paths. Run with:
+// go test -run NONE -bench . -benchmem
+
+// benchmark results are kept so the calls aren't optimized away.
+var (
+	benchDeal  [52]Card
+	benchBool  bool
+	benchCards []uint
+	benchCard  uint
+)
+
+// benchPlay plays the seed 1 script until the given function returns true
+// for the current position, or until the game is won.
+func benchPlay(done func(l *logic) bool) *logic {
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script {
+		if done(l) {
+			break
+		}
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	return l
+}
+
+// go test -run NONE -bench Shuffle
+func BenchmarkShuffle(b *testing.B) {
+	for i := range b.N {
+		benchDeal = shuffle(uint(i)%MAX_SEED, deck)
+	}
+}
+
+// go test -run NONE -bench NewGame
+func BenchmarkNewGame(b *testing.B) {
+	l := &logic{}
+	for i := range b.N {
+		l.NewGame(uint(i) % MAX_SEED)
+	}
+}
+
+// go test -run NONE -bench Interact
+func BenchmarkInteract(b *testing.B) {
+	l := &logic{}
+	l.NewGame(1)
+	deal := l.Board()
+	mv := seed1Script[0] // a card to a free cell.
+	for range b.N {
+		l.board = deal
+		l.moves.stack, l.moves.times = l.moves.stack[:1], l.moves.times[:1]
+		l.Interact(mv[0])
+		benchBool = l.Interact(mv[1])
+	}
+}
+
+// go test -run NONE -bench AutoMoveCard
+func BenchmarkAutoMoveCard(b *testing.B) {
+	l := benchPlay(func(l *logic) bool {
+		check := &logic{board: l.board, moves: &moves{}, playerMoved: true}
+		return check.AutoMoveCard()
+	})
+	board, played := l.Board(), len(l.moves.stack)
+	l.playerMoved = true
+	for range b.N {
+		l.board = board
+		l.moves.stack, l.moves.times = l.moves.stack[:played], l.moves.times[:played]
+		benchBool = l.AutoMoveCard()
+	}
+}
+
+// go test -run NONE -bench GetSequence
+func BenchmarkGetSequence(b *testing.B) {
+	longest := func(l *logic) (lead uint) {
+		for cid := AC; cid <= KS; cid++ {
+			if len(l.getSequence(cid)) > len(l.getSequence(lead)) {
+				lead = cid
+			}
+		}
+		return lead
+	}
+	l := benchPlay(func(l *logic) bool { return len(l.getSequence(longest(l))) >= 4 })
+	lead := longest(l)
+	for range b.N {
+		benchCards = l.getSequence(lead)
+	}
+}
+
+// go test -run NONE -bench CardAt
+func BenchmarkCardAt(b *testing.B) {
+	l := &logic{}
+	l.NewGame(1)
+	for i := range b.N {
+		benchCard = l.cardAt(uint(i) % (MAX_BOARD_ID + 1))
+	}
+}
+
+// go test -run NONE -bench FullGame
+func BenchmarkFullGame(b *testing.B) {
+	for range b.N {
+		l := benchPlay(func(l *logic) bool { return false })
+		benchBool = l.IsGameWon()
+	}
+}
@@ -4,6 +4,12 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -42,6 +48,22 @@ func TestShuffle(t *testing.T) {
 			}
 		}
 	}
+
+	// a larger deck deals every card once.
+	double := append(deck[:], deck[:]...)
+	for i := range double {
+		double[i].ID = uint(i)
+	}
+	for _, seed := range []uint{0, 1, MAX_SEED} {
+		deal := shuffleDeck(seed, double)
+		dealt := map[uint]bool{}
+		for _, c := range deal {
+			dealt[c.ID] = true
+		}
+		if len(deal) != len(double) || len(dealt) != len(double) {
+			t.Errorf("seed %d: expected %d unique cards got %d", seed, len(double), len(dealt))
+		}
+	}
 }
 
 // go test -run Next
@@ -52,6 +74,78 @@ func TestNextInFoundation(t *testing.T) {
 	}
 }
 
+// go test -run Describe
+func TestDescribeMove(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	deal := l.Board()
+
+	// undo a move to the freecell: 6H is last in the 4th cascade.
+	l.Interact(H6)
+	if !l.Interact(EMPTY_PILE1) {
+		t.Fatalf("expected 6H to move to a freecell")
+	}
+	popped := l.Board()
+	l.Undo()
+	if got := describeMove(l.Board(), popped); got != "6H->free" {
+		t.Errorf("expected 6H->free got %s", got)
+	}
+
+	// constructed moves from the seed 1 deal.
+	onCard, onEmpty, toFoundation, group, sequence := deal, deal, deal, deal, deal
+	onCard[C9] = deal[TC] + 8 // 9C is last in cascade 2, TC is last in cascade 8.
+	onEmpty[H6] = 15
+	toFoundation[AH] = FH
+	group[H6], group[C9] = 0, 1
+	sequence[C9], sequence[H6] = deal[TC]+8, deal[TC]+16
+	tests := []struct {
+		to   [52]uint
+		want string
+	}{
+		{deal, ""},
+		{onCard, "9C->TC"},
+		{onEmpty, "6H->empty"},
+		{toFoundation, "AH->F"},
+		{group, "2 cards"},
+		{sequence, "9C->TC"},
+	}
+	for _, tt := range tests {
+		if got := describeMove(deal, tt.to); got != tt.want {
+			t.Errorf("expected %q got %q", tt.want, got)
+		}
+	}
+}
+
+// go test -run MoveNumber
+func TestMoveNumber(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	check := func(want int) {
+		t.Helper()
+		if got := l.MoveNumber(); got != want || got != len(l.moves.stack)-1 {
+			t.Errorf("expected move %d got %d with stack depth %d", want, got, len(l.moves.stack))
+		}
+	}
+	check(0)
+	l.Interact(H6)
+	l.Interact(EMPTY_PILE1) // 6H to a freecell.
+	check(1)
+	l.Interact(C9)
+	l.Interact(EMPTY_PILE1 + 1) // 9C to a freecell.
+	check(2)
+	l.Undo()
+	check(1)
+	l.Undo()
+	l.Undo() // can't undo the deal.
+	check(0)
+	if l.MoveCount() == l.MoveNumber() {
+		t.Errorf("expected undos to count towards the score")
+	}
+	if got := moveNumberText(14); got != "move 14" {
+		t.Errorf("unexpected move number text %q", got)
+	}
+}
+
 // Check the random algorithm against published deals for a given seed.
 // eg: https://freecellgamesolutions.com/fcs/?game=999999
 var games = map[uint][]string{
@@ -101,3 +195,1010 @@ var games = map[uint][]string{
 		"QH", "KC", "JD", "7D",
 	},
 }
+
+// seed1Script wins the seed 1 deal. Each scripted move is a card pick
+// followed by a place pick, with all the available auto moves played
+// after each move.
+var seed1Script = [][2]uint{
+	{D3, EMPTY_PILE1 + 0}, {C2, EMPTY_PILE1 + 1}, {C8, EMPTY_PILE1 + 1},
+	{JH, QC}, {D4, EMPTY_PILE1 + 2}, {TC, JH}, {D7, EMPTY_PILE1 + 3}, {S7, D8},
+	{C3, C2}, {H3, H2}, {H5, C6}, {H6, S7}, {QS, KH}, {C9, TH}, {D7, S8},
+	{C6, D7}, {H8, C9}, {H4, H3}, {H5, H4}, {H6, H5}, {JS, EMPTY_PILE1 + 3},
+	{D5, C6}, {JH, QS}, {C6, EMPTY_PILE1 + 12}, {S6, D7}, {D6, S7},
+	{S3, EMPTY_PILE1 + 15}, {C4, C3}, {QC, KD}, {H7, H6}, {H8, H7}, {D8, C9},
+	{S4, S3}, {D7, EMPTY_PILE1 + 13}, {JS, EMPTY_PILE1 + 15},
+	{S8, EMPTY_PILE1 + 3}, {TD, JS}, {C5, C4}, {D5, S6}, {C6, C5}, {JH, QC},
+	{TS, EMPTY_PILE1 + 12}, {D9, TC}, {C8, D9}, {TS, EMPTY_PILE1 + 1}, {S9, TD},
+	{H9, H8}, {D8, S9}, {D7, C8}, {C9, EMPTY_PILE1 + 10}, {TH, H9}, {D8, C9},
+	{QH, KS}, {JS, QD}, {C8, EMPTY_PILE1 + 12}, {S8, D9}, {QD, EMPTY_PILE1 + 13},
+	{S5, S4}, {JC, QH}, {D8, S9}, {C9, EMPTY_PILE1 + 3}, {TC, EMPTY_PILE1 + 10},
+	{JH, TH}, {D7, S8}, {JC, EMPTY_PILE1 + 11}, {QH, JH}, {QS, EMPTY_PILE1 + 15},
+	{KH, QH}, {C7, C6}, {C8, C7}, {C9, C8}, {JC, EMPTY_PILE1 + 3},
+	{S8, EMPTY_PILE1 + 11}, {TS, EMPTY_PILE1 + 12}, {QS, EMPTY_PILE1 + 1},
+	{D9, TS}, {TC, C9}, {JC, TC}, {QC, JC}, {S8, D9}, {QS, KD},
+	{KS, EMPTY_PILE1 + 1}, {KC, QC}, {KD, EMPTY_PILE1 + 0},
+}
+
+// Plays the seed 1 deal to a win using the same logic calls as the UI.
+// go test -run FullGame
+func TestFullGame(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	freecellMoves, sequenceMoves, autoMoves := 0, 0, 0
+	for i, mv := range seed1Script {
+		before := l.Board()
+		if l.Interact(mv[0]) || !slices.Contains(l.GetSelected(), mv[0]) {
+			t.Fatalf("move %d: could not select %s", i, getCard(mv[0]).Sym)
+		}
+		if !l.Interact(mv[1]) {
+			t.Fatalf("move %d: could not place %s on %d", i, getCard(mv[0]).Sym, mv[1])
+		}
+		if l.isFreecell(l.board[mv[0]]) {
+			freecellMoves++
+		}
+		if moved := len(boardMoves(before, l.Board())); moved > 1 {
+			sequenceMoves++
+		}
+		for l.AutoMoveCard() {
+			autoMoves++
+		}
+	}
+	if !l.IsGameWon() {
+		dumpBoard(l.Board())
+		t.Fatalf("expected a won game")
+	}
+	if freecellMoves == 0 || sequenceMoves == 0 || autoMoves == 0 {
+		t.Errorf("expected freecell:%d sequence:%d auto:%d moves", freecellMoves, sequenceMoves, autoMoves)
+	}
+
+	// all cards are on the foundations with the kings on top.
+	for cid, bid := range l.Board() {
+		want := getCard(uint(cid)).Suit + 4
+		if getCard(uint(cid)).Rank != KING {
+			want += HIDDEN_CARD
+		}
+		if bid != want {
+			t.Errorf("card %s at %d expected %d", getCard(uint(cid)).Sym, bid, want)
+		}
+	}
+	if got := l.MoveNumber(); got != 112 || autoMoves != 28 {
+		t.Errorf("expected 112 moves with 28 auto moves, got %d with %d auto moves", got, autoMoves)
+	}
+}
+
+// go test -run SingleCard
+func TestSingleCardMoves(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script[:5] { // JH on QC with a free cell.
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		for l.AutoMoveCard() {
+		}
+	}
+	if l.cardAt(l.board[QC]+8) != JH || !l.isLastInCascade(JH) {
+		t.Fatalf("expected a QC-JH sequence")
+	}
+
+	// supermoves allow the sequence to be selected.
+	l.Interact(QC)
+	if got := l.GetSelected(); len(got) != 2 {
+		t.Errorf("expected 2 selected cards, got %v", got)
+	}
+	l.clearSelected()
+
+	// single card moves only allow the last card to be selected.
+	l.singleCardMoves = true
+	if seq := l.getSequence(QC); len(seq) != 0 {
+		t.Errorf("expected no sequence, got %v", seq)
+	}
+	if l.Interact(QC); l.isSelectionActive() {
+		t.Errorf("expected QC not selectable")
+	}
+	if l.movableStackSize(false) != 1 || l.movableStackSize(true) != 1 {
+		t.Errorf("expected a movable stack size of 1")
+	}
+	l.Interact(JH)
+	if got := l.GetSelected(); len(got) != 1 || got[0] != JH {
+		t.Errorf("expected JH selected, got %v", got)
+	}
+}
+
+// go test -run SeedForLayout
+func TestSeedForLayout(t *testing.T) {
+	l := &logic{}
+	layout := strings.Join(games[1], " ")
+	if seed, ok := l.SeedForLayout(layout); !ok || seed != 1 {
+		t.Errorf("expected seed 1 got %d %t", seed, ok)
+	}
+
+	// lower case and multi line layouts are accepted.
+	rows := ""
+	for i, sym := range games[1] {
+		rows += strings.ToLower(sym)
+		if (i+1)%8 == 0 {
+			rows += "\n"
+		} else {
+			rows += " "
+		}
+	}
+	if seed, ok := l.SeedForLayout(rows); !ok || seed != 1 {
+		t.Errorf("expected seed 1 got %d %t", seed, ok)
+	}
+
+	// custom and invalid layouts don't have a seed.
+	custom := slices.Clone(games[1])
+	custom[0], custom[1] = custom[1], custom[0]
+	invalid := slices.Clone(games[1])
+	invalid[0] = invalid[1]
+	for _, layout := range []string{
+		strings.Join(custom, " "),  // not a classic deal.
+		strings.Join(invalid, " "), // duplicate card.
+		strings.Join(games[1][:51], " "),
+		"",
+	} {
+		if seed, ok := l.SeedForLayout(layout); ok {
+			t.Errorf("expected no seed got %d for %q", seed, layout)
+		}
+	}
+
+	// custom layouts are dealt without a seed.
+	l.NewGame(1)
+	if l.NewCustomGame(strings.Join(invalid, " ")) || l.custom || l.gameSeed != 1 {
+		t.Errorf("expected an invalid layout to keep the current game")
+	}
+	if !l.NewCustomGame(strings.Join(custom, " ")) || !l.custom || l.gameSeed != 0 {
+		t.Fatalf("expected a custom game")
+	}
+	if l.deal[0].Sym != custom[0] || l.deal[1].Sym != custom[1] || l.MoveNumber() != 0 {
+		t.Errorf("expected the custom deal got %s %s", l.deal[0].Sym, l.deal[1].Sym)
+	}
+	if l.NewGame(1); l.custom {
+		t.Errorf("expected a seeded game")
+	}
+}
+
+// go test -run Supermove
+func TestSupermoveCells(t *testing.T) {
+	tests := []struct {
+		size, free, empty int
+		toEmpty           bool
+		cells, cascades   int
+	}{
+		{1, 4, 0, false, 0, 0}, // single cards don't use free cells.
+		{2, 1, 0, false, 1, 0},
+		{3, 4, 0, false, 2, 0},
+		{5, 4, 0, false, 4, 0},
+		{4, 1, 1, false, 1, 1}, // 2 cards held in the empty cascade.
+		{4, 1, 1, true, 1, 0},  // the empty cascade is the destination.
+		{8, 1, 3, false, 1, 3},
+		{6, 0, 2, false, 0, 2},
+	}
+	for _, tt := range tests {
+		cells, cascades := supermoveCells(tt.size, tt.free, tt.empty, tt.toEmpty)
+		if cells != tt.cells || cascades != tt.cascades {
+			t.Errorf("%+v: got %d cells %d cascades", tt, cells, cascades)
+		}
+	}
+
+	// the QC-JH sequence needs the one empty free cell.
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script[:5] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	if got := l.reservedFreecells(); len(got) != 0 {
+		t.Errorf("expected no reserved cells without a selection, got %v", got)
+	}
+	l.Interact(QC)
+	if got := l.reservedFreecells(); len(got) != 1 || !l.emptyPile(got[0]) {
+		t.Errorf("expected one empty reserved cell, got %v", got)
+	}
+	l.Interact(QC) // deselect
+	l.Interact(JH)
+	if got := l.reservedFreecells(); len(got) != 0 {
+		t.Errorf("expected no reserved cells for a single card, got %v", got)
+	}
+}
+
+// go test -run UndoSelection
+func TestUndoSelection(t *testing.T) {
+	l := &logic{undoKeepsSelection: true}
+	l.NewGame(1)
+	deal := l.Board()
+	l.Interact(D3)
+	l.Interact(EMPTY_PILE1) // 3D to a freecell.
+
+	// 6H can still be selected after the undo.
+	l.Interact(H6)
+	l.Undo()
+	if !l.isSelected(H6) {
+		t.Errorf("expected 6H to stay selected")
+	}
+	l.clearSelected()
+
+	// the card uncovered by 3D is covered again after the undo.
+	l.Interact(D3)
+	l.Interact(EMPTY_PILE1)
+	uncovered := l.cardAt(deal[D3] - 8)
+	l.Interact(uncovered)
+	if !l.isSelected(uncovered) {
+		t.Fatalf("expected %s selected", getCard(uncovered).Sym)
+	}
+	l.Undo()
+	if l.isSelectionActive() {
+		t.Errorf("expected %s to be deselected", getCard(uncovered).Sym)
+	}
+
+	// the selection is always cleared when the option is off.
+	l.undoKeepsSelection = false
+	l.Interact(D3)
+	l.Interact(EMPTY_PILE1)
+	l.Interact(H6)
+	l.Undo()
+	if l.isSelectionActive() {
+		t.Errorf("expected no selection")
+	}
+}
+
+// go test -run DropValidity
+func TestDropValidity(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	if show, _ := l.dropValidity(TC); show {
+		t.Errorf("expected nothing to show without a selection")
+	}
+	l.Interact(C9) // 9C is last in its cascade.
+	tests := []struct {
+		target      uint
+		show, valid bool
+	}{
+		{EMPTY_PILE1, true, true},       // empty freecell.
+		{EMPTY_PILE1 + FC, true, false}, // foundation needs an ace.
+		{H6, true, false},               // 9C does not go on 6H.
+		{AC, true, false},               // AC is buried in a cascade.
+		{C9, false, false},              // the selected card.
+		{NO_HIT, false, false},          // nothing under the mouse.
+	}
+	for _, tt := range tests {
+		show, valid := l.dropValidity(tt.target)
+		if show != tt.show || valid != tt.valid {
+			t.Errorf("target %d: expected %t %t got %t %t", tt.target, tt.show, tt.valid, show, valid)
+		}
+	}
+
+	// the scripted 6H to 7S move is valid.
+	l.NewGame(1)
+	for _, mv := range seed1Script[:11] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	l.Interact(H6)
+	if show, valid := l.dropValidity(S7); !show || !valid {
+		t.Errorf("expected 6H to be placeable on 7S")
+	}
+}
+
+// go test -run Timeline
+func TestTimeline(t *testing.T) {
+	l := &logic{}
+	if l.Timeline() != nil {
+		t.Errorf("expected no timeline before the deal")
+	}
+	l.moves = &moves{}
+	if l.Timeline() != nil {
+		t.Errorf("expected no timeline without moves")
+	}
+	l.NewGame(1)
+	if len(l.Timeline()) != 0 {
+		t.Errorf("expected an empty timeline")
+	}
+	for _, mv := range seed1Script[:10] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	l.Undo()
+	timeline := l.Timeline()
+	if len(timeline) != l.MoveNumber() {
+		t.Errorf("expected %d move times got %d", l.MoveNumber(), len(timeline))
+	}
+	for i := 1; i < len(timeline); i++ {
+		if timeline[i] < timeline[i-1] || timeline[0] < 0 {
+			t.Errorf("move %d time went backwards", i)
+		}
+	}
+}
+
+// go test -run DuplicateRecord
+func TestDuplicateRecord(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script[:2] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+	}
+	number, count := l.MoveNumber(), l.MoveCount()
+	if l.moves.record(l.Board()) {
+		t.Errorf("expected an unchanged board to not be recorded")
+	}
+	if l.MoveNumber() != number || l.MoveCount() != count || len(l.Timeline()) != number {
+		t.Errorf("expected move %d count %d got move %d count %d", number, count, l.MoveNumber(), l.MoveCount())
+	}
+
+	// a single undo still reverts the last move.
+	before := l.PreviousBoard()
+	l.Undo()
+	if l.Board() != before {
+		t.Errorf("expected undo to revert the last move")
+	}
+}
+
+// go test -run RandomMoves
+func TestRandomMoves(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2)) // repeatable random moves.
+	for seed := uint(1); seed <= 100; seed++ {
+		l := &logic{}
+		l.NewGame(seed)
+		for i := 0; i < 80; i++ {
+			legal := l.LegalMoves()
+			if len(legal) == 0 {
+				break // stuck or won.
+			}
+			mv := legal[rnd.IntN(len(legal))]
+			l.Interact(mv[0])
+			if !l.Interact(mv[1]) {
+				t.Fatalf("seed %d move %d: legal move %s to %d failed", seed, i, getCard(mv[0]).Sym, mv[1])
+			}
+			if rnd.IntN(4) == 0 {
+				l.AutoMoveAll()
+			}
+			if rnd.IntN(10) == 0 {
+				l.Undo()
+			}
+			if err := l.ValidateBoard(); err != nil {
+				dumpBoard(l.Board())
+				t.Fatalf("seed %d move %d: %s", seed, i, err)
+			}
+
+			// won games have all the kings showing on the foundations.
+			kings := 0
+			for suit := CLB; suit <= SPD; suit++ {
+				if getCard(l.cardAt(suit+FC)).Rank == KING {
+					kings++
+				}
+			}
+			if l.IsGameWon() != (kings == 4) {
+				t.Fatalf("seed %d move %d: won %t with %d kings", seed, i, l.IsGameWon(), kings)
+			}
+		}
+	}
+
+	// corrupt boards are detected.
+	l := &logic{}
+	l.NewGame(1)
+	for name, corrupt := range map[string]func(b *[52]uint){
+		"shared":   func(b *[52]uint) { b[AC] = b[KS] },
+		"gap":      func(b *[52]uint) { b[AC] = MAX_BOARD_ID - 7 },
+		"invalid":  func(b *[52]uint) { b[AC] = MAX_BOARD_ID + 1 },
+		"wrong":    func(b *[52]uint) { b[AC] = FD },
+		"unbuilt":  func(b *[52]uint) { b[C2] = FC },
+		"unburied": func(b *[52]uint) { b[AC] = FC + HIDDEN_CARD },
+	} {
+		saved := l.board
+		corrupt(&l.board)
+		if err := l.ValidateBoard(); err == nil {
+			t.Errorf("%s: expected an invalid board", name)
+		}
+		l.board = saved
+	}
+}
+
+// go test -run MovesExist
+func TestMovesExist(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(3, 4)) // repeatable random moves.
+	stuck := 0
+	for seed := uint(1); seed <= 100; seed++ {
+		l := &logic{}
+		l.NewGame(seed)
+		l.singleCardMoves = seed%4 == 0
+		for i := 0; i < 60; i++ {
+			legal := l.LegalMoves()
+			if l.MovesExist() != (len(legal) > 0) {
+				dumpBoard(l.Board())
+				t.Fatalf("seed %d move %d: moves exist %t with %d legal moves", seed, i, l.MovesExist(), len(legal))
+			}
+			if len(legal) == 0 {
+				if !l.IsGameWon() {
+					stuck++
+				}
+				break
+			}
+
+			// filling the free cells first gets stuck more often.
+			mv := legal[rnd.IntN(len(legal))]
+			for _, m := range legal {
+				if m[1] >= EMPTY_PILE1 && m[1] < EMPTY_PILE1+FC && rnd.IntN(2) == 0 {
+					mv = m
+					break
+				}
+			}
+			l.Interact(mv[0])
+			l.Interact(mv[1])
+			if rnd.IntN(3) == 0 {
+				l.AutoMoveAll()
+			}
+		}
+	}
+	if stuck == 0 {
+		t.Errorf("expected some games to get stuck")
+	}
+}
+
+// go test -run FoundationComplete
+func TestFoundationComplete(t *testing.T) {
+	for done := CLB; done <= SPD; done++ {
+		l := &logic{}
+		l.NewGame(1)
+		for suit := CLB; suit <= SPD; suit++ {
+			if l.FoundationComplete(suit) {
+				t.Fatalf("suit %d: expected an incomplete foundation on the deal", suit)
+			}
+		}
+
+		// build the suit foundation up to the queen, then the king.
+		for rank := ACES; rank < KING; rank++ {
+			l.board[rank*4+done] = done + FC + HIDDEN_CARD
+		}
+		l.board[QUEN*4+done] = done + FC
+		if l.FoundationComplete(done) {
+			t.Errorf("suit %d: expected the queen foundation to be incomplete", done)
+		}
+		l.board[QUEN*4+done] = done + FC + HIDDEN_CARD
+		l.board[KING*4+done] = done + FC
+		for suit := CLB; suit <= SPD; suit++ {
+			if l.FoundationComplete(suit) != (suit == done) {
+				t.Errorf("suit %d: expected complete %t", suit, suit == done)
+			}
+		}
+	}
+}
+
+// go test -run SeedByDifficulty
+func TestSeedByDifficulty(t *testing.T) {
+	l := &logic{}
+	for _, band := range []DifficultyBand{EasyDeals, MediumDeals, HardDeals} {
+		seed, ok := l.FindSeedByDifficulty(band)
+		if !ok {
+			t.Fatalf("expected a seed in band %v", band)
+		}
+		rating, cached := l.ratings[seed]
+		if !cached || rating < band.Min || rating >= band.Max {
+			t.Errorf("seed %d: expected a cached rating in band %v got %d %t", seed, band, rating, cached)
+		}
+	}
+	if rating := l.DealRating(UnsolvableGames[0]); rating != 100 {
+		t.Errorf("expected unsolvable games to rate 100 got %d", rating)
+	}
+	if _, ok := l.FindSeedByDifficulty(DifficultyBand{100, 101}); ok {
+		t.Errorf("expected unsolvable games to be skipped")
+	}
+}
+
+// orderedBoard returns a board where each suit is split over two
+// cascades in descending rank order, with the aces in the free cells.
+func orderedBoard() (board [52]uint) {
+	for cid := AC; cid <= KS; cid++ {
+		c := getCard(cid)
+		switch {
+		case c.Rank == ACES:
+			board[cid] = c.Suit // free cell.
+		case c.Rank >= SEVN:
+			board[cid] = 8 + (KING-c.Rank)*8 + c.Suit*2
+		default:
+			board[cid] = 8 + (SIXS-c.Rank)*8 + c.Suit*2 + 1
+		}
+	}
+	return board
+}
+
+// go test -run AutoComplete
+func TestAutoComplete(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	if l.CanAutoComplete() || l.AutoComplete() || l.MoveNumber() != 0 {
+		t.Fatalf("expected the deal to need playing")
+	}
+	l.board = orderedBoard()
+	l.moves.record(l.board)
+	if err := l.ValidateBoard(); err != nil {
+		t.Fatal(err)
+	}
+	if !l.CanAutoComplete() || !l.AutoComplete() || !l.IsGameWon() {
+		t.Fatalf("expected an ordered board to auto complete")
+	}
+	if l.MoveNumber() != 1+52 {
+		t.Errorf("expected a move for each card got %d", l.MoveNumber()-1)
+	}
+	for i, board := range l.moves.stack {
+		l.board = board
+		if err := l.ValidateBoard(); err != nil {
+			t.Fatalf("move %d: %s", i, err)
+		}
+	}
+}
+
+// go test -run Foundations
+func TestFoundations(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	for suit, stack := range l.Foundations() {
+		if len(stack) != 0 {
+			t.Fatalf("suit %d: expected an empty foundation on the deal got %v", suit, stack)
+		}
+	}
+
+	// the first moves put AC, 2C, and AS on the foundations.
+	for _, mv := range seed1Script[:2] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	want := [4][]uint{{AC, C2}, nil, nil, {AS}}
+	for suit, stack := range l.Foundations() {
+		if !slices.Equal(stack, want[suit]) {
+			t.Errorf("suit %d: expected %v got %v", suit, want[suit], stack)
+		}
+	}
+
+	// a won game has every card in order.
+	l.board = orderedBoard()
+	l.AutoComplete()
+	for suit, stack := range l.Foundations() {
+		if len(stack) != 13 {
+			t.Fatalf("suit %d: expected 13 cards got %d", suit, len(stack))
+		}
+		for rank, cid := range stack {
+			if c := getCard(cid); c.Suit != uint(suit) || c.Rank != uint(rank) {
+				t.Errorf("suit %d rank %d: got %s", suit, rank, c.Sym)
+			}
+		}
+	}
+}
+
+// go test -run InteractErr
+func TestInteractErr(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	check := func(pick uint, wantMoved bool, want error) {
+		t.Helper()
+		moved, err := l.InteractErr(pick)
+		if moved != wantMoved || !errors.Is(err, want) {
+			t.Errorf("pick %d: expected %t %v got %t %v", pick, wantMoved, want, moved, err)
+		}
+	}
+	check(EMPTY_PILE1, false, ErrNotSelectable) // can't select an empty pile.
+	check(seed1Script[0][0], false, nil)        // select.
+	check(seed1Script[0][0], false, nil)        // deselect.
+	check(seed1Script[0][0], false, nil)
+	check(seed1Script[0][1], true, nil) // move.
+
+	// 6H can't go on a foundation.
+	check(H6, false, nil)
+	check(EMPTY_PILE1+FC, false, ErrNotPlaceable)
+	var placement *PlacementError
+	l.Interact(H6)
+	if _, err := l.InteractErr(EMPTY_PILE1 + FS); !errors.As(err, &placement) || placement.Selected != H6 {
+		t.Errorf("expected a placement error for 6H got %v", err)
+	}
+	if placement != nil && placement.Error() != "6H on pile 7: selection can't be placed there" {
+		t.Errorf("unexpected message %q", placement.Error())
+	}
+}
+
+// go test -run InvalidPick
+func TestInvalidPick(t *testing.T) {
+	var log bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&log, nil)))
+
+	l := &logic{}
+	l.NewGame(1)
+	garbage := []uint{KS + 1, 99, EMPTY_PILE1 - 1, EMPTY_PILE16 + 1, MAX_BOARD_ID, NO_CARD, HIDDEN_CARD, NO_HIT, ^uint(0)}
+	for _, selected := range []uint{NO_CARD, seed1Script[0][0]} {
+		if selected != NO_CARD {
+			l.Interact(selected)
+		}
+		board, moves := l.Board(), l.MoveNumber()
+		for _, pick := range garbage {
+			if l.Interact(pick) || l.Board() != board || l.MoveNumber() != moves || l.selected != selected {
+				t.Errorf("pick %d with %d selected: expected no change", pick, selected)
+			}
+			var placement *PlacementError
+			if _, err := l.InteractErr(pick); !errors.Is(err, ErrInvalidPick) || !errors.As(err, &placement) {
+				t.Errorf("pick %d: expected an invalid pick error got %v", pick, err)
+			}
+		}
+	}
+	if log.Len() > 0 {
+		t.Errorf("expected invalid picks to be ignored quietly: %s", log.String())
+	}
+
+	// valid picks still work after the garbage.
+	if !l.Interact(seed1Script[0][1]) {
+		t.Errorf("expected the selected card to move")
+	}
+}
+
+// go test -run CanMove
+func TestCanMove(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	l.Interact(C9) // selection must not change.
+	if !l.CanMove(H6) || l.CanMove(AC) || l.CanMove(EMPTY_PILE1) {
+		t.Errorf("expected only the last cascade cards to move")
+	}
+	tests := []struct {
+		card, pick uint
+		want       bool
+	}{
+		{H6, EMPTY_PILE1, true},       // empty freecell.
+		{H6, EMPTY_PILE1 + FH, false}, // foundation needs an ace.
+		{H6, C9, false},               // 6H does not go on 9C.
+		{AC, EMPTY_PILE1, false},      // AC is buried in a cascade.
+		{H6, H6, false},               // not onto itself.
+		{H6, NO_HIT, false},           // nothing picked.
+	}
+	for _, tt := range tests {
+		if got := l.CanMoveTo(tt.card, tt.pick); got != tt.want {
+			t.Errorf("%s to %d: expected %t", getCard(tt.card).Sym, tt.pick, tt.want)
+		}
+	}
+	if l.selected != C9 {
+		t.Errorf("expected 9C to stay selected got %s", getCard(l.selected).Sym)
+	}
+
+	// foundation cards never move.
+	l.clearSelected()
+	for _, mv := range seed1Script[:2] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+	}
+	l.AutoMoveAll()
+	moved := 0
+	for cid := AC; cid <= KS; cid++ {
+		if !l.isFoundation(l.board[cid]) {
+			continue
+		}
+		moved++
+		if l.CanMove(cid) || l.CanMoveTo(cid, EMPTY_PILE1) {
+			t.Errorf("expected foundation card %s to not move", getCard(cid).Sym)
+		}
+	}
+	if moved == 0 {
+		t.Errorf("expected foundation cards")
+	}
+
+	// the scripted 6H to 7S move is possible.
+	l.NewGame(1)
+	for _, mv := range seed1Script[:11] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	if !l.CanMove(H6) || !l.CanMoveTo(H6, S7) || l.isSelectionActive() {
+		t.Errorf("expected 6H to move to 7S without selecting it")
+	}
+}
+
+// go test -run FirstMoveGate
+func TestFirstMoveGate(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	if l.AutoMoveCard() {
+		t.Fatalf("expected no auto moves before the first move")
+	}
+
+	// play until a card can be auto moved, without auto moving it.
+	var played Bookmark
+	for _, mv := range seed1Script {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		check := &logic{board: l.board, moves: &moves{}, playerMoved: true}
+		if check.AutoMoveCard() {
+			played = l.Bookmark()
+			break
+		}
+		l.AutoMoveAll()
+	}
+	if len(played.History) < 2 {
+		t.Fatalf("expected a position with an auto move after several moves")
+	}
+
+	// a restored bookmark has earlier moves, but no player moves.
+	restored := &logic{}
+	if err := restored.RestoreBookmark(played); err != nil {
+		t.Fatal(err)
+	}
+	if restored.AutoMoveCard() {
+		t.Errorf("expected no auto moves for a restored position")
+	}
+
+	// a head start deals a position with moves already on the stack.
+	head := &logic{}
+	head.NewGame(1)
+	for _, board := range restored.moves.stack[1:] {
+		head.moves.record(board)
+	}
+	head.board = restored.board
+	if head.AutoMoveCard() {
+		t.Errorf("expected no auto moves for a head start")
+	}
+
+	// the first player move allows auto moves, even after undoing it.
+	mv := head.LegalMoves()[0]
+	head.Interact(mv[0])
+	if !head.Interact(mv[1]) || !head.playerMoved {
+		t.Fatalf("expected a player move")
+	}
+	head.Undo()
+	if !head.AutoMoveCard() {
+		t.Errorf("expected auto moves after the first player move")
+	}
+}
+
+// go test -run AceBlockers
+func TestAceBlockers(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+
+	// the deal stacks each later card on the card 8 places before it.
+	aces, blockers := l.AceBlockers()
+	if len(aces) != 4 {
+		t.Fatalf("expected 4 aces got %v", aces)
+	}
+	want := []uint{}
+	for i, c := range l.deal {
+		if c.Rank == ACES {
+			for above := i + 8; above < len(l.deal); above += 8 {
+				want = append(want, l.deal[above].ID)
+			}
+		}
+	}
+	slices.Sort(want)
+	slices.Sort(blockers)
+	if !slices.Equal(blockers, want) {
+		t.Errorf("expected blockers %v got %v", want, blockers)
+	}
+
+	// a constructed board: 2C and KD on AH, AS in a free cell.
+	l.board = [52]uint{}
+	for cid := range l.board {
+		l.board[cid] = HIDDEN_CARD + getCard(uint(cid)).Suit + 4
+	}
+	l.board[AC], l.board[AD] = FC, FD // foundation tops.
+	l.board[AH], l.board[C2], l.board[KD] = 9, 17, 25
+	l.board[AS] = 2
+	if got := l.CardsAbove(AH); !slices.Equal(got, []uint{C2, KD}) {
+		t.Errorf("expected 2C KD above AH got %v", got)
+	}
+	if got := l.CardsAbove(KD); len(got) != 0 {
+		t.Errorf("expected nothing above the last card got %v", got)
+	}
+	aces, blockers = l.AceBlockers()
+	if !slices.Equal(aces, []uint{AH, AS}) || !slices.Equal(blockers, []uint{C2, KD}) {
+		t.Errorf("expected AH AS blocked by 2C KD got %v %v", aces, blockers)
+	}
+}
+
+// go test -run ThreeCells
+func TestThreeCells(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	l.singleCardMoves, l.closedCells = variantRules(variantThreeCells)
+	if l.emptyFreeCells() != 3 {
+		t.Fatalf("expected 3 free cells got %d", l.emptyFreeCells())
+	}
+	for _, legal := range l.LegalMoves() {
+		if legal[1] == EMPTY_PILE1+3 {
+			t.Fatalf("expected the last free cell to be closed")
+		}
+	}
+	l.Interact(H6)
+	if l.Interact(EMPTY_PILE1 + 3) {
+		t.Errorf("expected no move to the closed free cell")
+	}
+	l.Interact(H6)
+	if !l.Interact(EMPTY_PILE1 + 2) {
+		t.Errorf("expected a move to an open free cell")
+	}
+}
+
+// go test -run SuitChain
+func TestSuitChain(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+
+	// a constructed board: AH to 4H up, 5H in a free cell, 6H at the end
+	// of a cascade, and 7H covered by 8H.
+	l.board = [52]uint{}
+	for cid := range l.board {
+		l.board[cid] = HIDDEN_CARD + getCard(uint(cid)).Suit + 4
+	}
+	l.board[H4] = FH // foundation top.
+	l.board[H5], l.board[H6], l.board[H7], l.board[H8] = 0, 8, 9, 17
+	start := l.board
+	if cid, ok := foundationMove(l.PreviousBoard(), l.board); !ok || cid != H4 {
+		t.Errorf("expected 4H as the foundation move got %d %t", cid, ok)
+	}
+	if cid, ok := foundationMove(start, start); ok {
+		t.Errorf("expected no foundation move got %d", cid)
+	}
+
+	// the chain stops at the first rank that isn't available.
+	moves := l.MoveNumber()
+	if sent := l.SendSuitChain(H4); !slices.Equal(sent, []uint{H5, H6}) {
+		t.Fatalf("expected 5H 6H got %v", sent)
+	}
+	if l.board[H6] != FH || l.board[H5] != FH+HIDDEN_CARD || l.MoveNumber() != moves+2 {
+		t.Errorf("expected 6H on the foundation after 2 moves got %d %d", l.board[H6], l.MoveNumber()-moves)
+	}
+	if sent := l.SendSuitChain(H6); len(sent) != 0 {
+		t.Errorf("expected covered 7H to stop the chain got %v", sent)
+	}
+	if sent := l.SendSuitChain(H5); len(sent) != 0 {
+		t.Errorf("expected no chain from a buried card got %v", sent)
+	}
+
+	// uncovering the next rank continues the chain.
+	l.board[H8] = 1
+	if sent := l.SendSuitChain(H6); !slices.Equal(sent, []uint{H7, H8}) {
+		t.Errorf("expected 7H 8H got %v", sent)
+	}
+	if sent := l.SendSuitChain(KS); len(sent) != 0 {
+		t.Errorf("expected kings to end the chain got %v", sent)
+	}
+}
+
+// go test -run PreviousBoard
+func TestPreviousBoard(t *testing.T) {
+	l := &logic{}
+	if got := l.PreviousBoard(); got != l.board {
+		t.Errorf("expected the current board before a deal")
+	}
+	l.NewGame(1)
+	deal := l.Board()
+	if got := l.PreviousBoard(); got != deal {
+		t.Errorf("expected the deal right after a new game")
+	}
+	l.Interact(seed1Script[0][0])
+	l.Interact(seed1Script[0][1])
+	if l.MoveNumber() != 1 || l.PreviousBoard() != deal {
+		t.Errorf("expected the deal after one move, at move %d", l.MoveNumber())
+	}
+}
+
+// go test -run NewlySafe
+func TestNewlySafe(t *testing.T) {
+	l := &logic{}
+	for cid := range l.board {
+		l.board[cid] = HIDDEN_CARD + getCard(uint(cid)).Suit + 4 // out of play.
+	}
+
+	// AC covered by KD, AH in a free cell, and 2H at the end of a cascade.
+	l.board[AC], l.board[KD], l.board[AH], l.board[H2] = 8, 16, 0, 10
+	before := l.board
+	if safe := l.safeCards(); !slices.Equal(safe, []uint{AH}) {
+		t.Fatalf("expected AH to be safe got %v", safe)
+	}
+
+	// moving KD exposes AC.
+	l.board[KD] = 9
+	if got := newlySafeCards(before, l.board); !slices.Equal(got, []uint{AC}) {
+		t.Errorf("expected AC to become safe got %v", got)
+	}
+
+	// sending AH up makes 2H the next heart.
+	before = l.board
+	l.board[AH] = FH
+	if got := newlySafeCards(before, l.board); !slices.Equal(got, []uint{H2}) {
+		t.Errorf("expected 2H to become safe got %v", got)
+	}
+	if got := newlySafeCards(l.board, l.board); len(got) != 0 {
+		t.Errorf("expected nothing new without a move got %v", got)
+	}
+}
+
+// go test -run LoadFromHistory
+func TestLoadFromHistory(t *testing.T) {
+	played := &logic{}
+	played.NewGame(1)
+	for _, mv := range seed1Script[:30] {
+		played.Interact(mv[0])
+		played.Interact(mv[1])
+		played.AutoMoveAll()
+	}
+	history, want := played.History(), played.Board()
+	if len(history) != len(played.moves.stack)-1 {
+		t.Fatalf("expected a move for each board, got %d for %d", len(history), len(played.moves.stack)-1)
+	}
+
+	// loading the history reproduces the same moves and position.
+	loaded := &logic{}
+	if err := loaded.LoadFromHistory(1, history); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(loaded.moves.stack, played.moves.stack) || loaded.board != played.board {
+		t.Fatalf("expected the loaded game to match the played game")
+	}
+	if loaded.Undo(); loaded.Board() != played.moves.stack[len(played.moves.stack)-2] {
+		t.Errorf("expected undo to return to the previous move")
+	}
+
+	// auto moves continue from the loaded position.
+	pending := &logic{}
+	pending.NewGame(1)
+	pending.Interact(seed1Script[0][0])
+	pending.Interact(seed1Script[0][1])
+	pending.AutoMoveAll()
+	pending.Interact(seed1Script[1][0])
+	pending.Interact(seed1Script[1][1]) // leaves a safe foundation move.
+	resumed := &logic{}
+	resumed.NewGame(1)
+	resumed.Interact(seed1Script[0][0])
+	if err := resumed.LoadFromHistory(1, pending.History()); err != nil {
+		t.Fatal(err)
+	}
+	if len(resumed.GetSelected()) != 0 || !resumed.AutoMoveCard() {
+		t.Errorf("expected no selection and an auto move after loading")
+	}
+
+	// an illegal move is reported and leaves the game unchanged.
+	bad := slices.Clone(history)
+	bad[4] = [2]uint{KS, EMPTY_PILE1 + FC}
+	err := played.LoadFromHistory(1, bad)
+	var placement *PlacementError
+	if err == nil || !errors.As(err, &placement) || !strings.Contains(err.Error(), "move 5") {
+		t.Fatalf("expected move 5 to be rejected, got %v", err)
+	}
+	if played.board != want {
+		t.Errorf("expected a failed load to keep the current game")
+	}
+}
+
+// go test -run SuggestUnblock
+func TestSuggestUnblock(t *testing.T) {
+	tight := func(tail uint) *logic {
+		l := &logic{moves: &moves{}}
+		for cid := range l.board {
+			l.board[cid] = HIDDEN_CARD + getCard(uint(cid)).Suit + 4 // out of play.
+		}
+		l.board[H5], l.board[C9], l.board[D9], l.board[JS] = 0, 1, 2, 3 // full free cells.
+		for i, cid := range []uint{tail, KD, KH, KS, KC, C2, S2, D2} {
+			l.board[cid] = 8 + uint(i) // no empty cascades.
+		}
+		return l
+	}
+
+	// the only move frees a free cell.
+	l := tight(C6)
+	move, ok := l.SuggestUnblock()
+	if !ok || move != [2]uint{H5, C6} {
+		t.Fatalf("expected 5H onto 6C got %s to %d %t", getCard(move[0]).Sym, move[1], ok)
+	}
+	if hint, _ := l.Hint(); hint != move {
+		t.Errorf("expected the hint to free the free cell")
+	}
+
+	// nothing can be freed.
+	l = tight(D6)
+	if move, ok := l.SuggestUnblock(); ok {
+		t.Errorf("expected a deadlock got %s to %d", getCard(move[0]).Sym, move[1])
+	}
+	if l.MovesExist() {
+		t.Errorf("expected no moves")
+	}
+}
@@ -6,10 +6,15 @@ package main
 // main.go initializes the game logic and starts the game engine.
 
 import (
+	"context"
 	"embed"
 	"io"
 	"log/slog"
 	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gazed/vu"
@@ -25,9 +30,24 @@ var setLogging func(w io.Writer) = func(w io.Writer) {
 	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelInfo})))
 }
 
-// numberpadExists is true if the platform allows the player to type digits.
-// This is needed for editing the game seed.
-var numberpadExists = true // true for macos, windows. ios overrides to false.
+// platformNumberpad is true if the platform normally has a keyboard
+// for typing digits. See numberpadExists.
+var platformNumberpad = true // true for macos, windows. ios overrides to false.
+
+// numberpadExists returns true if the player can type digits to edit the
+// game seed. The player preference wins, otherwise digits can be typed if
+// the platform normally has a keyboard or a digit key has been pressed,
+// ie: an ipad with a keyboard. Without a numberpad the game seed is
+// changed using the hold and press seed dial.
+func numberpadExists(pref string, platform, typed bool) bool {
+	switch pref {
+	case numberpadOn:
+		return true
+	case numberpadOff:
+		return false
+	}
+	return platform || typed
+}
 
 // defaultSize returns reasonable screen size that works for macos and windows.
 // This is over-written in the save file once the player resizes or repositions
@@ -43,13 +63,14 @@ var defaultSize func() (x, y, w, h int) = func() (x, y, w, h int) {
 func main() {
 
 	// initialize logging. Overwrite log file each run.
-	logfile := savePath(saveDir(), "info.log")                  // create dir if necessary
+	logfile := savePath(dataDir(), "info.log")                  // create dir if necessary
 	f, err := os.OpenFile(logfile, os.O_RDWR|os.O_CREATE, 0666) // overwrite previous log file
 	if err != nil {
 		slog.Error("log file open", "err", err)
 		return
 	}
 	setLogging(f)
+	slog.SetDefault(slog.New(loadFailureHandler{slog.Default().Handler()}))
 	defer f.Close()
 
 	// override vu.load.ReadFile function to use embedded resources.
@@ -57,14 +78,17 @@ func main() {
 
 	// restore persistent game data, if any.
 	launch := &launcher{}
-	launch.save = newSave(saveDir(), "freecell.save")
+	launch.save = newSave(dataDir(), "freecell.save")
 	launch.save.restore()
-	slog.Info("starting game", "seed", launch.save.Seed)
+	launch.save.checkWritable()
+	slog.Info("starting game", "seed", launch.save.Seed, "inProgress", launch.save.InProgress)
 
-	// use default window size if there was no save data.
+	// use default window size if there was no save data,
+	// or if the saved window is below the minimum size.
 	// tall and narrow dimensions are preferred.
 	firstLaunch := launch.save.Display.Ww == 0
-	if firstLaunch {
+	tooSmall := launch.save.Display.Ww < minWindowWidth || launch.save.Display.Wh < minWindowHeight
+	if firstLaunch || tooSmall {
 		x, y, w, h := defaultSize()
 		launch.save.persistWindow(x, y, w, h)
 	}
@@ -143,3 +167,84 @@ var assets embed.FS
 
 // embeddedReadFile used to override vu.load.ReadFile
 func embeddedReadFile(filepath string) ([]byte, error) { return assets.ReadFile(filepath) }
+
+// -----------------------------------------------------------------------------
+// asset load failures.
+// The engine only reports that some asset failed to load, so the failed
+// assets are found from the engine error logs.
+
+// engineLoadErrors are the engine error log messages for failed assets.
+var engineLoadErrors = map[string]bool{
+	"failed asset load":            true, // includes the asset filename.
+	"LoadMesh failed":              true,
+	"LoadTexture failed":           true,
+	"FontAtlas LoadTexture failed": true,
+	"LoadSound failed":             true,
+	"LoadShader failed":            true,
+	"unknown asset data":           true,
+}
+
+// failedAssets are the asset load failures logged so far.
+var failedAssets struct {
+	mu    sync.Mutex
+	names []string // asset filename, or the log message if not known.
+}
+
+// loadFailures returns the asset load failures logged so far.
+func loadFailures() []string {
+	failedAssets.mu.Lock()
+	defer failedAssets.mu.Unlock()
+	return slices.Clone(failedAssets.names)
+}
+
+// loadFailureHandler records the asset load failures logged by the
+// engine and then passes all logs through to the wrapped handler.
+type loadFailureHandler struct{ slog.Handler }
+
+// Handle implements slog.Handler.
+func (h loadFailureHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelError && engineLoadErrors[r.Message] {
+		name := r.Message
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "filename" {
+				name = a.Value.String()
+				return false
+			}
+			return true
+		})
+		failedAssets.mu.Lock()
+		failedAssets.names = append(failedAssets.names, name)
+		failedAssets.mu.Unlock()
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h loadFailureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return loadFailureHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h loadFailureHandler) WithGroup(name string) slog.Handler {
+	return loadFailureHandler{h.Handler.WithGroup(name)}
+}
+
+// fatalLoadErrors returns true if the game can't run without the failed
+// assets, ie: shaders, meshes, and fonts. Missing images and sounds are
+// cosmetic, so the game continues without them. Failures that can't be
+// identified are treated as fatal.
+func fatalLoadErrors(failed []string) bool {
+	if len(failed) == 0 {
+		return true // the engine reported errors that weren't logged.
+	}
+	for _, name := range failed {
+		switch {
+		case name == "LoadTexture failed" || name == "LoadSound failed":
+		case strings.EqualFold(path.Ext(name), ".png"):
+		case strings.EqualFold(path.Ext(name), ".wav"):
+		default:
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,9 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+
+	"github.com/gazed/vu"
 )
 
 // override the default setLogging to dump debugging logs directly
@@ -21,4 +24,28 @@ func init() {
 		// used to find loading and startup issues.
 		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	}
+
+	// U deals the next unsolvable game. Used to check unsolvable handling.
+	// Y replays the last recorded game. Used to reproduce reported bugs.
+	debugKey = func(gm *game, press int32) {
+		if press == vu.KY && gm.anim == nil {
+			gm.startReplay()
+		}
+		if press == vu.KU {
+			seed := nextUnsolvableSeed(gm.save.Seed)
+			slog.Debug("dealing unsolvable game", "seed", seed)
+			gm.save.persistSeed(seed)
+			gm.resetBoard()
+		}
+	}
+}
+
+// nextUnsolvableSeed returns the first unsolvable game after the given seed,
+// wrapping around to the first unsolvable game.
+func nextUnsolvableSeed(seed uint) uint {
+	index, found := slices.BinarySearch(UnsolvableGames, seed)
+	if found {
+		index++
+	}
+	return UnsolvableGames[index%len(UnsolvableGames)]
 }
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+//go:build debug
+
+package main
+
+import (
+	"testing"
+)
+
+// go test -tags debug -run Unsolvable
+func TestNextUnsolvableSeed(t *testing.T) {
+	seed := uint(1)
+	for i := range UnsolvableGames {
+		seed = nextUnsolvableSeed(seed)
+		if seed != UnsolvableGames[i] {
+			t.Fatalf("expected seed %d got %d", UnsolvableGames[i], seed)
+		}
+		if tlogic.IsGameSolvable(seed) {
+			t.Errorf("expected seed %d to be unsolvable", seed)
+		}
+	}
+	if seed = nextUnsolvableSeed(seed); seed != UnsolvableGames[0] {
+		t.Errorf("expected wrap to %d got %d", UnsolvableGames[0], seed)
+	}
+}
@@ -21,7 +21,7 @@ func init() {
 		slog.SetDefault(slog.New(slog.NewTextHandler(vu.ConsoleWriter(), &slog.HandlerOptions{Level: slog.LevelDebug})))
 	}
 
-	// override hasNumberpad to false as there is no nice way
-	// to enter digits on ios.
-	numberpadExists = false
+	// there is no nice way to enter digits on ios unless the
+	// player has a keyboard, see numberpadExists.
+	platformNumberpad = false
 }
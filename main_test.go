@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// go test -run LoadErrors
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		failed []string
+		fatal  bool
+	}{
+		{[]string{"crown.png", "FC.PNG"}, false},            // cosmetic images.
+		{[]string{"LoadTexture failed"}, false},             // cosmetic texture.
+		{[]string{"seed.png", "card.shd"}, true},            // shaders are needed.
+		{[]string{"card.glb"}, true},                        // meshes are needed.
+		{[]string{"96:hack.ttf"}, true},                     // fonts are needed.
+		{[]string{"FontAtlas LoadTexture failed"}, true},    // font textures too.
+		{[]string{"unknown asset data", "crown.png"}, true}, // unknown failures.
+		{nil, true}, // the engine reported failures that weren't logged.
+	}
+	for i, tt := range tests {
+		if got := fatalLoadErrors(tt.failed); got != tt.fatal {
+			t.Errorf("%d %v: expected fatal %t", i, tt.failed, tt.fatal)
+		}
+	}
+
+	// the failed assets are found from the engine error logs.
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(loadFailureHandler{slog.NewTextHandler(io.Discard, nil)}))
+	before := len(loadFailures())
+	slog.Error("failed asset load", "filename", "crown.png", "error", "missing")
+	slog.With("scene", 1).Error("LoadShader failed", "error", "bad")
+	slog.Error("unrelated failure", "filename", "other.png")
+	slog.Warn("failed asset load", "filename", "warning.png")
+	if got := loadFailures()[before:]; !slices.Equal(got, []string{"crown.png", "LoadShader failed"}) {
+		t.Errorf("expected the logged failures got %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// par.go looks up the par scores bundled for popular deals so that
+// players have a target alongside their own best score.
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// parData is the par score table, one "seed score" pair per line.
+//
+//go:embed assets/data/par.txt
+var parData string
+
+// parScores is the par score table keyed by seed, parsed on first use.
+var parScores = sync.OnceValue(func() map[uint]uint { return parseParScores(parData) })
+
+// parseParScores reads the par score table, skipping blank lines and
+// comments. Invalid lines are logged and skipped.
+func parseParScores(data string) map[uint]uint {
+	scores := map[uint]uint{}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			slog.Error("invalid par score", "line", i+1, "text", line)
+			continue
+		}
+		seed, err1 := strconv.ParseUint(fields[0], 10, 32)
+		score, err2 := strconv.ParseUint(fields[1], 10, 32)
+		if err1 != nil || err2 != nil || uint(seed) > MAX_SEED {
+			slog.Error("invalid par score", "line", i+1, "text", line)
+			continue
+		}
+		scores[uint(seed)] = uint(score)
+	}
+	return scores
+}
+
+// parScore returns the par score for the given seed.
+// Returns false if the seed has no par score.
+func parScore(seed uint) (score uint, ok bool) {
+	score, ok = parScores()[seed]
+	return score, ok
+}
+
+// parText is the par score display text.
+func parText(score uint) string {
+	return fmt.Sprintf("par %03d", score)
+}
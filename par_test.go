@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"testing"
+)
+
+// go test -run ParScore
+func TestParScore(t *testing.T) {
+	for seed, want := range map[uint]uint{1: 99, 3: 77, 1941: 83} {
+		if got, ok := parScore(seed); !ok || got != want {
+			t.Errorf("seed %d: expected par %d got %d %t", seed, want, got, ok)
+		}
+	}
+	for _, seed := range []uint{0, 5, 11_982, MAX_SEED} {
+		if got, ok := parScore(seed); ok {
+			t.Errorf("seed %d: expected no par got %d", seed, got)
+		}
+	}
+
+	// comments, blank lines, and bad lines are skipped.
+	scores := parseParScores("# comment\n\n 12 34 \n12\nx 5\n7 -1\n2000000 9\n")
+	if len(scores) != 1 || scores[12] != 34 {
+		t.Errorf("expected only seed 12 got %v", scores)
+	}
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+//go:build debug
+
+package main
+
+// replay_debug.go records the player actions that change the board so
+// that a reported bug can be reproduced by replaying them. Each deal
+// starts a new recording in the data directory.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// replayFile holds the recording for the latest deal.
+const replayFile = "freecell-replay.log"
+
+// replayStep is one recorded player action: a "seed" deal, a "custom"
+// layout deal, a "pick" of a card or empty pile, an "undo", a bookmark
+// "restore", or auto moves: "auto" for one card, "autoAll" for all safe
+// cards, and "complete" to finish the game.
+type replayStep struct {
+	at    time.Duration // time since the deal.
+	kind  string        // player action.
+	value uint          // seed, pick, or restored undos, otherwise 0.
+	text  string        // deal variant and custom layout, or restored history.
+}
+
+// recorder writes each player action to the replay file as it happens.
+type recorder struct {
+	file  string    // replay file path, set on the first recording.
+	start time.Time // when the recorded game was dealt.
+}
+
+// replayRecorder records the debug build games.
+var replayRecorder = &recorder{}
+
+// record appends the action to the replay file. A new deal replaces
+// the previous recording.
+func (r *recorder) record(kind string, value uint, text string) {
+	if r.file == "" {
+		r.file = savePath(dataDir(), replayFile)
+	}
+	flags := os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	if kind == "seed" || kind == "custom" {
+		r.start = time.Now()
+		flags = os.O_TRUNC | os.O_CREATE | os.O_WRONLY
+	}
+	f, err := os.OpenFile(r.file, flags, 0644)
+	if err != nil {
+		slog.Debug("replay record", "err", err)
+		return
+	}
+	defer f.Close()
+	step := replayStep{at: time.Since(r.start), kind: kind, value: value, text: text}
+	line := fmt.Sprintf("%d %s %d", step.at.Milliseconds(), step.kind, step.value)
+	if fields := strings.Fields(step.text); len(fields) > 0 {
+		line += " " + strings.Join(fields, " ") // keep the step on one line.
+	}
+	fmt.Fprintln(f, line)
+}
+
+// readReplay parses a recording, one "milliseconds kind value text" step
+// per line. The text is optional.
+func readReplay(r io.Reader) (steps []replayStep, err error) {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		var ms int64
+		step := replayStep{}
+		if _, err := fmt.Sscanf(scanner.Text(), "%d %s %d", &ms, &step.kind, &step.value); err != nil {
+			return nil, fmt.Errorf("replay line %d: %w", line, err)
+		}
+		step.at = time.Duration(ms) * time.Millisecond
+		if fields := strings.Fields(scanner.Text()); len(fields) > 3 {
+			step.text = strings.Join(fields[3:], " ")
+		}
+		steps = append(steps, step)
+	}
+	return steps, scanner.Err()
+}
+
+// replayAction plays one recorded action on the game logic.
+func replayAction(l *logic, step replayStep) {
+	switch step.kind {
+	case "seed":
+		l.singleCardMoves, l.closedCells = variantRules(step.text)
+		l.NewGame(step.value)
+	case "custom":
+		variant, layout, _ := strings.Cut(step.text, " ")
+		l.singleCardMoves, l.closedCells = variantRules(variant)
+		l.NewCustomGame(layout)
+	case "restore":
+		b := Bookmark{Seed: l.gameSeed, History: replayHistory(step.text), Undos: int(step.value)}
+		if err := l.RestoreBookmark(b); err != nil {
+			slog.Debug("replay restore", "err", err)
+		}
+	case "pick":
+		if step.value == NO_HIT {
+			l.clearSelected()
+			return
+		}
+		l.Interact(step.value)
+	case "undo":
+		l.Undo()
+	case "auto":
+		l.AutoMoveCard()
+	case "autoAll":
+		l.AutoMoveAll()
+	case "complete":
+		l.AutoComplete()
+	case "chain":
+		l.sendNextInSuit(step.value)
+	case "chainAll":
+		l.SendSuitChain(step.value)
+	default:
+		slog.Debug("ignoring replay step", "kind", step.kind)
+	}
+}
+
+// replayHistory parses the {pick, place} pairs of a recorded history,
+// written like [[pick place] [pick place]].
+func replayHistory(text string) (history [][2]uint) {
+	fields := strings.Fields(strings.NewReplacer("[", " ", "]", " ").Replace(text))
+	for i := 0; i+1 < len(fields); i += 2 {
+		var mv [2]uint
+		if _, err := fmt.Sscan(fields[i], &mv[0]); err != nil {
+			return nil
+		}
+		if _, err := fmt.Sscan(fields[i+1], &mv[1]); err != nil {
+			return nil
+		}
+		history = append(history, mv)
+	}
+	return history
+}
+
+// replay is an Animation that plays the recorded actions through the
+// game loop at their recorded times.
+type replay struct {
+	gm      *game
+	steps   []replayStep
+	elapsed time.Duration
+}
+
+// Run implements the Animation interface.
+func (r *replay) Run(delta time.Duration) Animation {
+	r.elapsed += delta
+	for len(r.steps) > 0 && r.steps[0].at <= r.elapsed {
+		if r.steps[0].kind == "seed" {
+			r.gm.save.Seed = r.steps[0].value // show the replayed game.
+		}
+		replayAction(r.gm.logic, r.steps[0])
+		r.steps = r.steps[1:]
+		r.gm.redrawBoard()
+	}
+	if len(r.steps) == 0 {
+		return nil
+	}
+	return r
+}
+
+// record the debug build games.
+func init() { debugRecord = replayRecorder.record }
+
+// startReplay replays the recorded game, starting from its deal.
+func (gm *game) startReplay() {
+	f, err := os.Open(savePath(dataDir(), replayFile))
+	if err != nil {
+		slog.Debug("replay", "err", err)
+		return
+	}
+	defer f.Close()
+	steps, err := readReplay(f)
+	if err != nil || len(steps) == 0 || (steps[0].kind != "seed" && steps[0].kind != "custom") {
+		slog.Debug("replay needs a recorded deal", "err", err)
+		return
+	}
+	slog.Debug("replaying game", "seed", steps[0].value, "steps", len(steps))
+	gm.clearTrails()
+	gm.anim = &replay{gm: gm, steps: steps}
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+//go:build debug
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// go test -tags debug -run Replay
+func TestReplay(t *testing.T) {
+	rec := &recorder{file: filepath.Join(t.TempDir(), replayFile)}
+
+	// play part of a game, recording the actions as the game would.
+	l := &logic{}
+	l.singleCardMoves, l.closedCells = variantRules(variantThreeCells)
+	l.NewGame(1)
+	rec.record("seed", 1, variantThreeCells)
+	var mark Bookmark
+	for i, mv := range seed1Script[:8] {
+		for _, pick := range mv {
+			l.Interact(pick)
+			rec.record("pick", pick, "")
+		}
+		if i == 3 {
+			l.Undo()
+			rec.record("undo", 0, "")
+			mark = l.Bookmark()
+		}
+		for l.AutoMoveCard() {
+			rec.record("auto", 0, "")
+		}
+	}
+	if err := l.RestoreBookmark(mark); err != nil {
+		t.Fatal(err)
+	}
+	rec.record("restore", uint(l.moves.undos), fmt.Sprint(l.History()))
+	l.Interact(seed1Script[4][0])
+	rec.record("pick", seed1Script[4][0], "")
+	l.clearSelected()
+	rec.record("pick", NO_HIT, "")
+
+	// the recording replays to the same board, under the same rules.
+	steps := readSteps(t, rec.file)
+	if len(steps) == 0 || steps[0].kind != "seed" || steps[0].value != 1 || steps[0].text != variantThreeCells {
+		t.Fatalf("expected the recording to start with the deal got %v", steps)
+	}
+	replayed := &logic{}
+	replayed.NewGame(2)
+	for _, step := range steps {
+		replayAction(replayed, step)
+	}
+	if replayed.Board() != l.Board() || replayed.MoveNumber() != l.MoveNumber() || replayed.selected != l.selected {
+		t.Errorf("expected the replay at move %d to match move %d", replayed.MoveNumber(), l.MoveNumber())
+	}
+	if replayed.closedCells != 1 || replayed.moves.undos != l.moves.undos {
+		t.Errorf("expected a closed cell and %d undos got %d %d", l.moves.undos, replayed.closedCells, replayed.moves.undos)
+	}
+
+	// a custom deal starts a new recording with its layout.
+	layout := ""
+	for _, c := range shuffle(7, deck) {
+		layout += c.Sym + "\n"
+	}
+	custom := &logic{}
+	custom.NewCustomGame(layout)
+	rec.record("custom", 0, variantClassic+" "+layout)
+	steps = readSteps(t, rec.file)
+	if len(steps) != 1 || steps[0].kind != "custom" {
+		t.Fatalf("expected a new recording got %v", steps)
+	}
+	replayAction(replayed, steps[0])
+	if replayed.Board() != custom.Board() || !replayed.custom || replayed.closedCells != 0 {
+		t.Errorf("expected the custom deal to replay")
+	}
+
+	// a new deal starts a new recording.
+	rec.record("seed", 7, variantClassic)
+	if steps := readSteps(t, rec.file); len(steps) != 1 || steps[0].value != 7 {
+		t.Errorf("expected a new recording got %v", steps)
+	}
+}
+
+// readSteps reads the recorded steps from the given replay file.
+func readSteps(t *testing.T, file string) []replayStep {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	steps, err := readReplay(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return steps
+}
@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// rules.go separates the freecell rules from the game logic so that
+// other solitaire games could reuse the board, rendering, animation,
+// save, and seed handling.
+
+import (
+	"log/slog"
+)
+
+// Rules decide how the cards are dealt and which cards can be moved
+// where. The game logic keeps the board and the moves, and asks its
+// Rules about each pick. Freecell is the default.
+type Rules interface {
+
+	// Deal returns the starting board location of each card
+	// for the shuffled deck.
+	Deal(deal [52]Card) (board [52]uint)
+
+	// InSequence returns true if card b can be placed on card a
+	// in a cascade.
+	InSequence(a, b Card) bool
+
+	// NextInFoundation returns true if card b is the next card for the
+	// suit foundation topped by card a. Card a is InvalidCard for an
+	// empty foundation.
+	NextInFoundation(suit uint, a, b Card) bool
+
+	// CanSelect returns true if the picked card can be selected.
+	CanSelect(l *logic, pick uint) bool
+
+	// CanPlace returns true if the selected cards can be placed on the
+	// picked card or empty pile.
+	CanPlace(l *logic, pick uint) bool
+}
+
+// FreecellRules are the default rules.
+var FreecellRules Rules = freecell{}
+
+// freecell implements the freecell Rules. Selecting and placing cards
+// checks sequences through the logic, so rules that embed freecell and
+// only change the sequences are used throughout.
+type freecell struct{}
+
+// Deal puts the shuffled cards into the 8 cascades, left to right.
+func (r freecell) Deal(deal [52]Card) (board [52]uint) {
+	for cid := AC; cid <= KS; cid++ {
+		board[deal[cid].ID] = cid + 8
+	}
+	return board
+}
+
+// InSequence returns true if b can be placed on a in a cascade,
+// ie: returns true if Card b is 1 rank less than card a and is the opposite color.
+func (r freecell) InSequence(a, b Card) bool {
+	return (b.Rank == (a.Rank - 1)) && b.Color != a.Color
+}
+
+// NextInFoundation returns true if Card b is the next
+// card that should be placed in the foundation pile for the given suit.
+func (r freecell) NextInFoundation(suit uint, a, b Card) bool {
+	if suit > SPD {
+		slog.Error("NextInFoundation invalid suit")
+		return false
+	}
+	onEmpty := a.ID == NO_CARD && b.Suit == suit && b.Rank == ACES
+	onCard := a.ID != NO_CARD && b.Suit == suit && b.Rank == a.Rank+1
+	return onEmpty || onCard
+}
+
+// CanSelect returns true if the given board location has a selectable card.
+// Can only pick the cards, not the empty piles.
+func (r freecell) CanSelect(l *logic, pick uint) bool {
+	if !isCard(pick) {
+		return false
+	}
+	boardPick := l.board[pick] // board location of the picked card.
+
+	// foundation cards can never be picked up.
+	// FUTURE: make this an option. Some implementations allow cards to
+	//         be moved from the foundation back to the cascade.
+	if l.isFoundation(boardPick) {
+		return false
+	}
+
+	// check that the pick can be placed somewhere.
+	if l.isCascade(boardPick) || l.isFreecell(boardPick) {
+		seq := l.getSequence(pick)
+		if len(seq) <= 0 {
+			return false
+		}
+		c := getCard(seq[0]) // top card in picked sequence.
+
+		// check valid moves for single selections
+		if len(seq) == 1 {
+			if l.emptyFreeCells() > 0 {
+				return true // a single card can be moved to an empty cell.
+			}
+
+			// check if the card can be moved to a foundation pile.
+			foundationPileID := c.Suit + 4
+			if l.emptyPile(foundationPileID) && c.Rank == ACES {
+				return true
+			}
+			topCard := getCard(l.cardAt(foundationPileID))
+			if l.isNextInFoundation(c.Suit, topCard, c) {
+				return true
+			}
+		}
+		if l.emptyCascades() > 0 {
+			return true // a valid sequence can be moved to an empty cascade
+		}
+
+		// check the last card of each cascade to see if the first
+		// card in the sequence one can be placed on it.
+		return l.canMoveToCascade(seq[0])
+	}
+	return false
+}
+
+// CanPlace returns true if the selected cards can be placed
+// on the picked card or empty pile.
+func (r freecell) CanPlace(l *logic, pick uint) bool {
+	selects := l.GetSelected()
+
+	// consider the empty piles
+	if pick >= EMPTY_PILE1 && pick <= EMPTY_PILE16 {
+		s := getCard(selects[0])
+		pileID := pick - EMPTY_PILE1
+
+		// always valid to place a card on an empty freecell.
+		if l.isFreecell(pileID) && len(selects) == 1 {
+			return l.isOpenFreecell(pileID) && l.emptyPile(pileID)
+		}
+
+		// check placing a card on an empty foundation.
+		// The card must be an ACE matching the foundation suit.
+		if l.isFoundation(pileID) && len(selects) == 1 {
+			return (s.Suit == pileID-4) && s.Rank == ACES
+		}
+
+		// always valid to place a card on an empty cascade.
+		if pileID >= 8 && pileID <= 15 {
+			return l.emptyPile(pileID)
+		}
+
+		// should not reach here.
+		slog.Error("invalid card pick", "pick", pick)
+		return false
+	}
+
+	// the user picked a card in order to place the
+	// selected cards on the picked card.
+	cardID := uint(pick)
+	if l.isCard(cardID) {
+		p := getCard(cardID)
+		s := getCard(selects[0])
+		boardPick := l.board[cardID]
+
+		// if card is on a foundation pile, then it must be the next highest
+		// card rank and the same suit. Only valid for single selected card.
+		if l.isFoundation(boardPick) && len(selects) == 1 {
+			suit := boardPick - 4
+			return l.isNextInFoundation(suit, p, s)
+		}
+
+		// attempt to put the picked card onto the selected card.
+		// The pick card must be the last in the cascade and it must be
+		// the next highest rank and the opposite color from the top selected card.
+		if l.isCascade(boardPick) {
+			if l.isLastInCascade(cardID) {
+				return l.nextInSequence(p, s)
+			}
+			return false
+		}
+
+		// a picked card can't interact with cards in the freecells.
+		return false
+	}
+
+	// dev error: should never reach here
+	slog.Error("invalid CanPlace pick", "pick", pick)
+	return false
+}
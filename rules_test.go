@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"hash/fnv"
+	"testing"
+)
+
+// ruleDecisions appends each select and place decision on the current
+// board: whether each card can be selected, and then where it can go.
+func ruleDecisions(l *logic, out []byte) []byte {
+	selected := l.selected
+	defer func() { l.selected = selected }()
+	for cid := AC; cid <= KS; cid++ {
+		l.clearSelected()
+		if !l.canSelectCard(cid) {
+			out = append(out, 0)
+			continue
+		}
+		out = append(out, 1)
+		l.selected = cid
+		for pick := AC; pick <= KS; pick++ {
+			if pick != cid && l.canPlaceCard(pick) {
+				out = append(out, byte(pick))
+			}
+		}
+		for pick := EMPTY_PILE1; pick <= EMPTY_PILE16; pick++ {
+			pile := pick - EMPTY_PILE1
+			if l.cardAt(pile) != NO_CARD || (!l.isCascade(pile) && len(l.GetSelected()) > 1) {
+				continue
+			}
+			if l.canPlaceCard(pick) {
+				out = append(out, byte(pile+64))
+			}
+		}
+	}
+	return out
+}
+
+// rulesFingerprint hashes the rule decisions for a few deals and for
+// each position of a full game.
+func rulesFingerprint(rules Rules) uint64 {
+	decisions := []byte{}
+	l := &logic{rules: rules}
+	for _, seed := range []uint{617, 11_982, 1} {
+		l.NewGame(seed)
+		decisions = ruleDecisions(l, decisions)
+	}
+	for _, mv := range seed1Script {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+		decisions = ruleDecisions(l, decisions)
+	}
+	h := fnv.New64a()
+	h.Write(decisions)
+	return h.Sum64()
+}
+
+// bakersGame is freecell with cascades built down by suit.
+type bakersGame struct{ freecell }
+
+func (r bakersGame) InSequence(a, b Card) bool { return b.Rank == a.Rank-1 && b.Suit == a.Suit }
+
+// go test -run FreecellRules
+func TestFreecellRules(t *testing.T) {
+	// recorded from the logic before the rules were extracted.
+	const inlined = 11558340521106950379
+	if got := rulesFingerprint(nil); got != inlined {
+		t.Errorf("expected the default rules to match the inlined logic got %d", got)
+	}
+	if got := rulesFingerprint(FreecellRules); got != inlined {
+		t.Errorf("expected FreecellRules to match the inlined logic got %d", got)
+	}
+
+	// the deal is the same for any rules that keep the freecell layout.
+	l, baker := &logic{}, &logic{rules: bakersGame{}}
+	l.NewGame(1)
+	baker.NewGame(1)
+	if l.Board() != baker.Board() {
+		t.Errorf("expected the same deal")
+	}
+
+	// other rules plug in to the same logic: 9H goes on 10H, not 10S.
+	if !baker.nextInSequence(getCard(TH), getCard(H9)) || baker.nextInSequence(getCard(TS), getCard(H9)) {
+		t.Errorf("expected cascades built by suit")
+	}
+	if !l.nextInSequence(getCard(TS), getCard(H9)) || l.nextInSequence(getCard(TH), getCard(H9)) {
+		t.Errorf("expected cascades built by alternate colors")
+	}
+
+	// selecting and placing cards use the plugged in sequences.
+	for _, lg := range []*logic{l, baker} {
+		for cid := range lg.board {
+			lg.board[cid] = HIDDEN_CARD + getCard(uint(cid)).Suit + 4
+		}
+		lg.board[H9], lg.board[TH], lg.board[TS] = 0, 8, 9 // 9H in a free cell.
+		lg.selected = H9
+	}
+	if !baker.canPlaceCard(TH) || baker.canPlaceCard(TS) {
+		t.Errorf("expected 9H to go on 10H")
+	}
+	if l.canPlaceCard(TH) || !l.canPlaceCard(TS) {
+		t.Errorf("expected 9H to go on 10S")
+	}
+}
@@ -4,9 +4,17 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/gob"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path"
+	"reflect"
+	"slices"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,33 +23,240 @@ import (
 // game session and the next. Save needs to be public and visible for
 // the encoding package.
 type Save struct {
-	file string // Save file name.
+	file        string        // Save file name.
+	unsavedPlay time.Duration // play time since the total was last saved.
+	saveErr     error         // last save failure, nil once a save works.
 
 	// data saved to disk.
-	Seed    uint `yaml:"seed"` // current game.
-	Full    bool `yaml:"full"` // true if game is fullscreen.
-	Display struct {
+	Seed       uint `yaml:"seed"`       // current game.
+	InProgress bool `yaml:"inProgress"` // true once the current game has a player move.
+	Full       bool `yaml:"full"`       // true if game is fullscreen.
+	Display    struct {
 		Wx int `yaml:"wx"`
 		Wy int `yaml:"wy"`
 		Ww int `yaml:"ww"`
 		Wh int `yaml:"wh"`
 	} `yaml:"display,flow"` // last window location
-	Scores map[uint]uint `yaml:"scores"` // high scores for completed games
+	Scores map[uint]uint `yaml:"scores"`     // high scores for completed classic games
+	Stats  Stats         `yaml:"stats,flow"` // game statistics.
+
+	// lifetime play time, excluding time spent selecting games.
+	TotalPlayTime time.Duration `yaml:"totalPlayTime"`
+
+	// high scores for the variants other than classic.
+	VariantScores map[string]map[uint]uint `yaml:"variantScores"`
+
+	// fastest game clock for each won seed.
+	BestTimes map[uint]time.Duration `yaml:"bestTimes"`
+
+	// most recent win for each won seed.
+	WinDates map[uint]time.Time `yaml:"winDates"`
+
+	// the number of attempts it took to first win each game.
+	AttemptsToWin map[uint]uint `yaml:"attemptsToWin"`
+
+	// solver solution length for each won seed, when one was found.
+	SolverMoves map[uint]int `yaml:"solverMoves"`
+
+	// unwon attempts for each seed, ie: games started and then left.
+	Attempts map[uint]uint `yaml:"attempts"`
+
+	// the per seed records above for the variants other than classic.
+	VariantTimes         map[string]map[uint]time.Duration `yaml:"variantTimes"`
+	VariantWinDates      map[string]map[uint]time.Time     `yaml:"variantWinDates"`
+	VariantAttemptsToWin map[string]map[uint]uint          `yaml:"variantAttemptsToWin"`
+	VariantSolverMoves   map[string]map[uint]int           `yaml:"variantSolverMoves"`
+	VariantAttempts      map[string]map[uint]uint          `yaml:"variantAttempts"`
+
+	// named game positions that can be restored to retry a game.
+	Bookmarks map[string]Bookmark `yaml:"bookmarks"`
+
+	// games marked by the player, and recent games, most recent first.
+	Favorites []uint `yaml:"favorites,flow"`
+	Recent    []uint `yaml:"recent,flow"`
+
+	// player preferences.
+	UndoToast          bool    `yaml:"undoToast"`          // true to describe each undo.
+	AutoMoveDelay      int     `yaml:"autoMoveDelay"`      // milliseconds: -1 speeds up, 0 is instant.
+	MinMoveTime        int     `yaml:"minMoveTime"`        // milliseconds for the shortest card moves, up to 200.
+	AutoPlay           string  `yaml:"autoPlay"`           // when safe cards move to the foundations.
+	ShowMoveNumber     bool    `yaml:"showMoveNumber"`     // true to display the move number.
+	TiltCards          bool    `yaml:"tiltCards"`          // true to tilt cascade cards.
+	ReduceMotion       bool    `yaml:"reduceMotion"`       // true to move cards without animation.
+	MoveGhosts         bool    `yaml:"moveGhosts"`         // true to outline where moving cards started.
+	SingleCardMoves    bool    `yaml:"singleCardMoves"`    // true to disallow multi card moves.
+	UndoKeepsSelection bool    `yaml:"undoKeepsSelection"` // true to keep the selected card after an undo.
+	ActiveClock        bool    `yaml:"activeClock"`        // true to pause the game clock when idle.
+	ShowStats          bool    `yaml:"showStats"`          // true to display the game statistics.
+	TapTolerance       int     `yaml:"tapTolerance"`       // pixels a tap can move before it is a drag, 0 picks cards on press.
+	SwipeThreshold     int     `yaml:"swipeThreshold"`     // pixels a sideways swipe must move, zero to disable.
+	FixedCardSize      bool    `yaml:"fixedCardSize"`      // true to keep card size when the window resizes.
+	LeftHanded         bool    `yaml:"leftHanded"`         // true to mirror the buttons left to right.
+	DealCards          bool    `yaml:"dealCards"`          // true to deal new games card by card.
+	TutorialDone       bool    `yaml:"tutorialDone"`       // true once the tutorial has been played or skipped.
+	CardLabels         bool    `yaml:"cardLabels"`         // true to label cards in large text for small screens.
+	FrameLimit         int     `yaml:"frameLimit"`         // frames per second cap, 30 to 240. 0 for no cap.
+	IdleInvite         bool    `yaml:"idleInvite"`         // true to gently bob a suggested card when idle.
+	SeedLock           bool    `yaml:"seedLock"`           // true to prevent accidental game changes.
+	RepeatUntilWon     bool    `yaml:"repeatUntilWon"`     // true to redeal the current game until it is won.
+	TrailBudget        int     `yaml:"trailBudget"`        // most card trail quads per move. 0 for none, ie: low end devices.
+	Numberpad          string  `yaml:"numberpad"`          // when digits are typed to edit the game seed.
+	AnimateUndo        bool    `yaml:"animateUndo"`        // true to move undone cards back instead of snapping.
+	CompactSave        bool    `yaml:"compactSave"`        // true to write a faster binary save file, ie: mobile.
+	RankedPlay         bool    `yaml:"rankedPlay"`         // true to keep unsolvable deals out of the win streak.
+	ButtonScale        float64 `yaml:"buttonScale"`        // button size multiplier, 0.75 to 2.0, ie: larger for touch.
+	Variant            string  `yaml:"variant"`            // rules of play, switched between games.
+	CardShadows        bool    `yaml:"cardShadows"`        // true to draw card drop shadows. Off for low end devices.
+	ShowRemaining      bool    `yaml:"showRemaining"`      // true to display the cards not yet on the foundations.
+	SuitChain          bool    `yaml:"suitChain"`          // true to continue manual foundation moves up the suit.
+	ShowMoveLog        bool    `yaml:"showMoveLog"`        // true to display the last few moves.
+	ConfirmQuit        bool    `yaml:"confirmQuit"`        // true to need a second quit key press.
+	SafeHints          bool    `yaml:"safeHints"`          // true to pulse cards that become safe to auto move.
+	PileLayout         string  `yaml:"pileLayout"`         // where the free cells and foundations are drawn.
+	CollectWin         bool    `yaml:"collectWin"`         // true to sweep the cards into the foundations after a win.
+	HighlightColor     string  `yaml:"highlightColor"`     // selection color: a preset name or a custom "#rrggbb".
+	DealOrigin         string  `yaml:"dealOrigin"`         // where the cards are dealt from when dealing card by card.
+	HighContrast       bool    `yaml:"highContrast"`       // true for a dark board and stronger highlights.
+}
+
+// Stats are the player game statistics.
+type Stats struct {
+	Wins   int `yaml:"wins"`   // games won.
+	Streak int `yaml:"streak"` // games won in a row.
+}
+
+// Bookmark is a saved game position. History is the moves from the deal
+// up to the bookmarked position so that undo works after the bookmark is
+// restored. Older bookmarks kept the board after each move instead, which
+// is many times larger.
+type Bookmark struct {
+	Seed    uint       `yaml:"seed"`                 // bookmarked game.
+	History [][2]uint  `yaml:"history,flow"`         // {pick, place} for each move.
+	Undos   int        `yaml:"undos"`                // undos before the bookmark.
+	Moves   [][52]uint `yaml:"moves,flow,omitempty"` // older bookmarks: board after each move.
+}
+
+// moveNumber returns the number of moves to the bookmarked position.
+func (b Bookmark) moveNumber() int {
+	if len(b.Moves) > 0 {
+		return len(b.Moves) - 1
+	}
+	return len(b.History)
+}
+
+// AutoPlay preferences for moving safe cards to the foundations.
+const (
+	autoPlayOff      = "off"       // never auto move cards.
+	autoPlayOnMove   = "on-move"   // auto move cards after each move.
+	autoPlayOnDemand = "on-demand" // auto move cards when requested.
+)
+
+// Numberpad preferences for typing digits to edit the game seed.
+const (
+	numberpadAuto = "auto" // type digits if a keyboard is available.
+	numberpadOn   = "on"   // always type digits.
+	numberpadOff  = "off"  // never type digits, use the seed dial.
+)
+
+// Variant preferences for the rules of play. Each variant keeps its own
+// best scores.
+const (
+	variantClassic    = "classic"     // four free cells and supermoves.
+	variantThreeCells = "three-cells" // only three free cells.
+	variantSingleCard = "single-card" // one card moves at a time.
+)
+
+// variants in the order they are switched.
+var variants = []string{variantClassic, variantThreeCells, variantSingleCard}
+
+// PileLayout preferences for where the free cells and foundations are
+// drawn in the top row. The board locations are the same for each layout.
+const (
+	pileLayoutClassic         = "cells-left"       // free cells left, foundations right.
+	pileLayoutFoundationsLeft = "foundations-left" // foundations left, free cells right.
+	pileLayoutMirrored        = "mirrored"         // classic reversed, spades on the far left.
+)
+
+// pile layouts in the order they are switched.
+var pileLayouts = []string{pileLayoutClassic, pileLayoutFoundationsLeft, pileLayoutMirrored}
+
+// DealOrigin preferences for where the deck is when dealing card by card.
+const (
+	dealOriginTop  = "top-center"  // above the middle of the board.
+	dealOriginSeed = "seed-button" // the game number button.
+)
+
+// HighlightColor presets for the selected cards. Other colors can be
+// set in the save file as "#rrggbb".
+const (
+	highlightGold    = "gold"    // the original selection color.
+	highlightCyan    = "cyan"    // stands out on red and yellow boards.
+	highlightMagenta = "magenta" // stands out on green boards.
+	highlightWhite   = "white"   // stands out on dark boards.
+)
+
+// highlight presets in the order they are switched.
+var highlightColors = []string{highlightGold, highlightCyan, highlightMagenta, highlightWhite}
+
+// variantRules returns the logic rules for the given variant.
+func variantRules(variant string) (singleCardMoves bool, closedCells int) {
+	switch variant {
+	case variantThreeCells:
+		return false, 1
+	case variantSingleCard:
+		return true, 0
+	}
+	return false, 0
 }
 
 // newSave creates default persistent application state. The directory
 // is platform specific, eg: save_windows.go
 // The default starting seed is 000001.
 func newSave(dir, fname string) *Save {
-	s := &Save{Seed: 1, Scores: map[uint]uint{}}
+	s := &Save{Seed: 1, Scores: map[uint]uint{}, UndoToast: true, AutoMoveDelay: -1}
+	s.Attempts = map[uint]uint{}
+	s.VariantScores = map[string]map[uint]uint{}
+	s.BestTimes = map[uint]time.Duration{}
+	s.WinDates = map[uint]time.Time{}
+	s.AttemptsToWin = map[uint]uint{}
+	s.SolverMoves = map[uint]int{}
+	s.Bookmarks = map[string]Bookmark{}
+	s.AutoPlay = autoPlayOnMove
+	s.Numberpad = numberpadAuto
+	s.PileLayout = pileLayoutClassic
+	s.HighlightColor = highlightGold
+	s.DealOrigin = dealOriginTop
+	s.ButtonScale = 1.0
+	s.CardShadows = true
+	s.SafeHints = true
+	s.CollectWin = true
+	s.MinMoveTime = 100
+	s.SwipeThreshold = 120
 	s.file = savePath(dir, fname) //
 	return s
 }
 
+// dataDirEnv names the environment variable that overrides the
+// platform save directory, ie: for portable installs and testing.
+const dataDirEnv = "PUREFREECELL_DATA"
+
+// dataDir returns the directory for the save and log files. This is the
+// platform saveDir unless overridden by a creatable data directory.
+func dataDir() string {
+	if dir := os.Getenv(dataDirEnv); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err == nil {
+			return dir
+		}
+		slog.Warn("ignoring data directory", dataDirEnv, dir)
+	}
+	return saveDir()
+}
+
 // savePath returns the full path to the save file.
 // The save directory is created if it does not exist.
 func savePath(dir, fname string) string {
 	if err := os.MkdirAll(dir, 0755); err != nil {
+		slog.Warn("saving in the working directory", "dir", dir, "error", err)
 		dir = ""
 	}
 	return path.Join(dir, fname)
@@ -55,13 +270,107 @@ func (s *Save) persistWindow(x, y, w, h int) {
 	s.persist()
 }
 
+// resetWindow saves the default window location and size, ie: after
+// a monitor change leaves the saved window in an awkward place.
+func (s *Save) resetWindow() {
+	x, y, w, h := defaultSize()
+	s.persistWindow(x, y, w, h)
+}
+
 // persistSeed saves the game number while preserving
 // the other information.
 func (s *Save) persistSeed(seed uint) {
 	s.Seed = seed
+	s.addRecent(seed)
 	s.persist()
 }
 
+// addRecent moves the game to the front of the recent games,
+// forgetting the oldest games past maxRecent.
+func (s *Save) addRecent(seed uint) {
+	s.Recent = slices.DeleteFunc(s.Recent, func(r uint) bool { return r == seed })
+	s.Recent = slices.Insert(s.Recent, 0, seed)
+	s.Recent = s.Recent[:min(len(s.Recent), maxRecent)]
+}
+
+// toggleFavorite adds or removes the game from the favorites.
+// Returns true if the game is now a favorite.
+func (s *Save) toggleFavorite(seed uint) bool {
+	if i := slices.Index(s.Favorites, seed); i >= 0 {
+		s.Favorites = slices.Delete(s.Favorites, i, i+1)
+		return false
+	}
+	s.Favorites = append(s.Favorites, seed)
+	return true
+}
+
+// variant returns the active variant. Single card moves were a player
+// preference before variants, so they are the single card variant.
+func (s *Save) variant() string {
+	switch {
+	case s.SingleCardMoves:
+		return variantSingleCard
+	case s.Variant == variantThreeCells:
+		return variantThreeCells
+	}
+	return variantClassic
+}
+
+// setVariant changes the active variant.
+func (s *Save) setVariant(variant string) {
+	s.Variant = variant
+	s.SingleCardMoves = variant == variantSingleCard
+}
+
+// scores returns the high scores for the active variant.
+func (s *Save) scores() map[uint]uint {
+	return variantSeeds(&s.Scores, &s.VariantScores, s.variant())
+}
+
+// bestTimes returns the fastest game clocks for the active variant.
+func (s *Save) bestTimes() map[uint]time.Duration {
+	return variantSeeds(&s.BestTimes, &s.VariantTimes, s.variant())
+}
+
+// winDates returns the most recent wins for the active variant.
+func (s *Save) winDates() map[uint]time.Time {
+	return variantSeeds(&s.WinDates, &s.VariantWinDates, s.variant())
+}
+
+// attemptsToWin returns the attempts to first win for the active variant.
+func (s *Save) attemptsToWin() map[uint]uint {
+	return variantSeeds(&s.AttemptsToWin, &s.VariantAttemptsToWin, s.variant())
+}
+
+// attempts returns the unwon attempts for the active variant.
+func (s *Save) attempts() map[uint]uint {
+	return variantSeeds(&s.Attempts, &s.VariantAttempts, s.variant())
+}
+
+// solverMoves returns the solver solution lengths for the given variant.
+func (s *Save) solverMoves(variant string) map[uint]int {
+	return variantSeeds(&s.SolverMoves, &s.VariantSolverMoves, variant)
+}
+
+// variantSeeds returns the per seed records for the given variant.
+// The classic variant uses the classic records so that saves from before
+// variants keep their records. Missing maps are created.
+func variantSeeds[V any](classic *map[uint]V, variants *map[string]map[uint]V, variant string) map[uint]V {
+	if variant == variantClassic {
+		if *classic == nil {
+			*classic = map[uint]V{}
+		}
+		return *classic
+	}
+	if *variants == nil {
+		*variants = map[string]map[uint]V{}
+	}
+	if (*variants)[variant] == nil {
+		(*variants)[variant] = map[uint]V{}
+	}
+	return (*variants)[variant]
+}
+
 // persistFullscreen save the full screen preference while preserving
 // the other information.
 func (s *Save) persistFullScreen(fullScreen bool) {
@@ -69,24 +378,398 @@ func (s *Save) persistFullScreen(fullScreen bool) {
 	s.persist()
 }
 
+// ImportClassicScores merges best scores from classic microsoft freecell
+// game numbers into the saved scores. Existing better scores are kept.
+// Invalid game numbers and scores are skipped and reported as an error.
+func (s *Save) ImportClassicScores(classic map[uint]uint) error {
+	skipped := []uint{}
+	for game, score := range classic {
+		seed, ok := classicSeed(game)
+		if !ok || score == 0 {
+			skipped = append(skipped, game)
+			continue
+		}
+		if best, ok := s.Scores[seed]; !ok || score < best {
+			s.Scores[seed] = score
+		}
+	}
+	s.persist()
+	if len(skipped) > 0 {
+		return fmt.Errorf("skipped %d invalid classic games: %v", len(skipped), skipped)
+	}
+	return nil
+}
+
+// resetProgress clears the player scores and statistics while preserving
+// the current game, the window, and the player preferences.
+func (s *Save) resetProgress() {
+	s.Scores = map[uint]uint{}
+	s.VariantScores = map[string]map[uint]uint{}
+	s.Stats = Stats{}
+	s.Attempts = map[uint]uint{}
+	s.BestTimes = map[uint]time.Duration{}
+	s.WinDates = map[uint]time.Time{}
+	s.AttemptsToWin = map[uint]uint{}
+	s.VariantTimes = map[string]map[uint]time.Duration{}
+	s.VariantWinDates = map[string]map[uint]time.Time{}
+	s.VariantAttemptsToWin = map[string]map[uint]uint{}
+	s.VariantAttempts = map[string]map[uint]uint{}
+	s.persist()
+}
+
+// firstRun returns true for players that have not played any games.
+func (s *Save) firstRun() bool {
+	return !s.TutorialDone && len(s.Scores) == 0 && len(s.Attempts) == 0 && s.Stats == Stats{}
+}
+
+// recordWin updates the statistics for a won game.
+func (s *Save) recordWin() {
+	s.Stats.Wins++
+	s.Stats.Streak++
+	s.InProgress = false
+}
+
+// persistInProgress saves whether the current game has been played,
+// only writing the save file if it changed.
+func (s *Save) persistInProgress(inProgress bool) {
+	if s.InProgress != inProgress {
+		s.InProgress = inProgress
+		s.persist()
+	}
+}
+
+// recordTime keeps the game clock for a won seed if it is the fastest.
+func (s *Save) recordTime(seed uint, clock time.Duration) {
+	times := s.bestTimes()
+	if best, ok := times[seed]; !ok || clock < best {
+		times[seed] = clock
+	}
+}
+
+// recordWinDate keeps when the seed was most recently won.
+func (s *Save) recordWinDate(seed uint, won time.Time) { s.winDates()[seed] = won }
+
+// recordAttemptsToWin keeps the number of attempts it took to first win
+// the seed. It is called before the win is scored. Winning ends repeating
+// the game.
+func (s *Save) recordAttemptsToWin(seed uint) {
+	if _, won := s.scores()[seed]; !won {
+		s.attemptsToWin()[seed] = s.attempt(seed)
+	}
+	s.RepeatUntilWon = false
+}
+
+// attempt returns the attempt number of the current game of the seed.
+func (s *Save) attempt(seed uint) uint { return s.attempts()[seed] + 1 }
+
+// seedFixed returns true if the player can't change to another game,
+// either because the seed is locked or the game is being repeated.
+func (s *Save) seedFixed() bool { return s.SeedLock || s.RepeatUntilWon }
+
+// recordSolverMoves keeps the solver solution length for a seed
+// played under the given variant.
+func (s *Save) recordSolverMoves(variant string, seed uint, length int) {
+	s.solverMoves(variant)[seed] = length
+}
+
+// recordLoss updates the statistics for an abandoned game.
+func (s *Save) recordLoss(seed uint) {
+	s.Stats.Streak = 0
+	s.attempts()[seed] += 1
+}
+
+// hardestUnwon returns the seed with the most attempts that has never
+// been won in the active variant. The lowest seed is returned when
+// attempts are tied. Returns false if there are no unwon attempts.
+func (s *Save) hardestUnwon() (seed uint, ok bool) {
+	most, scores := uint(0), s.scores()
+	for game, attempts := range s.attempts() {
+		if _, won := scores[game]; won || attempts == 0 {
+			continue
+		}
+		if attempts > most || (attempts == most && game < seed) {
+			seed, most, ok = game, attempts, true
+		}
+	}
+	return seed, ok
+}
+
+// playSaveInterval is the play time accumulated between saves
+// of the total play time.
+const playSaveInterval = time.Minute
+
+// addPlayTime adds play time to the lifetime total. Returns true when
+// enough play time has accumulated that the total should be persisted.
+func (s *Save) addPlayTime(delta time.Duration) (persist bool) {
+	s.TotalPlayTime += delta
+	s.unsavedPlay += delta
+	if s.unsavedPlay < playSaveInterval {
+		return false
+	}
+	s.unsavedPlay = 0
+	return true
+}
+
+// persistBookmark saves a named game position, replacing any previous
+// bookmark with the same name.
+func (s *Save) persistBookmark(name string, b Bookmark) {
+	if s.Bookmarks == nil {
+		s.Bookmarks = map[string]Bookmark{}
+	}
+	s.Bookmarks[name] = b
+	s.persist()
+}
+
+// bookmarkName returns the bookmark name for a game position.
+// Names sort by game and then by move.
+func bookmarkName(seed uint, move int) string {
+	return fmt.Sprintf("%06d-%03d", seed, move)
+}
+
+// furthestBookmark returns the name of the bookmark with the most moves
+// for the given game. Returns false if the game has no bookmarks.
+func (s *Save) furthestBookmark(seed uint) (name string, ok bool) {
+	most := -1
+	for bname, b := range s.Bookmarks {
+		if b.Seed == seed && (b.moveNumber() > most || (b.moveNumber() == most && bname < name)) {
+			name, most, ok = bname, b.moveNumber(), true
+		}
+	}
+	return name, ok
+}
+
+// statsExportFile is the data directory file for exported statistics.
+const statsExportFile = "freecell-stats.csv"
+
+// exportStats writes the per seed statistics as CSV to the data directory
+// for players that want to analyze their games. Returns the file name.
+func (s *Save) exportStats() (file string, err error) {
+	var buf bytes.Buffer
+	if err = writeStatsCSV(&buf, s); err != nil {
+		return "", err
+	}
+	file = path.Join(path.Dir(s.file), statsExportFile)
+	return file, os.WriteFile(file, buf.Bytes(), 0644)
+}
+
+// writeStatsCSV writes a row for each seed that has been won or attempted,
+// in seed order. Best moves and times are blank for unwon seeds.
+func writeStatsCSV(w io.Writer, s *Save) error {
+	seeds := []uint{}
+	for seed := range s.Scores {
+		seeds = append(seeds, seed)
+	}
+	for seed := range s.Attempts {
+		if _, won := s.Scores[seed]; !won {
+			seeds = append(seeds, seed)
+		}
+	}
+	slices.Sort(seeds)
+	out := csv.NewWriter(w)
+	out.Write([]string{"seed", "won", "best_moves", "best_seconds", "attempts"})
+	for _, seed := range seeds {
+		moves, won := s.Scores[seed]
+		row := []string{fmt.Sprint(seed), fmt.Sprint(won), "", "", fmt.Sprint(s.Attempts[seed])}
+		if won {
+			row[2] = fmt.Sprint(moves)
+		}
+		if best, ok := s.BestTimes[seed]; ok && won {
+			row[3] = fmt.Sprintf("%.1f", best.Seconds())
+		}
+		out.Write(row)
+	}
+	out.Flush()
+	return out.Error()
+}
+
 // persist is called to record any user preferences. This is expected
 // to be called when a user preference changes.
+// The save file is written to a temporary file and then renamed so that
+// a crash during the write can't leave a partial save file. The previous
+// save file is kept as a backup.
 func (s *Save) persist() {
-	if data, err := yaml.Marshal(&s); err == nil {
-		if err = os.WriteFile(s.file, data, 0644); err != nil {
-			slog.Debug("save game state", "error", err)
-		}
-	} else {
-		slog.Debug("encode game state", "error", err)
+	s.saveFailed(s.write())
+}
+
+// write replaces the save file, keeping the previous file as a backup.
+func (s *Save) write() error {
+	data, err := s.encode()
+	if err != nil {
+		return fmt.Errorf("encode game state: %w", err)
+	}
+	tmp := s.file + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err = backupFile(s.file, s.file+".bak"); err != nil && !os.IsNotExist(err) {
+		slog.Debug("backup game state", "error", err)
 	}
+	return os.Rename(tmp, s.file) // replaces the save file in one step.
+}
+
+// backupFile links, or copies if links aren't supported, the file to the
+// backup file. The file itself is left in place so that there is always
+// a save file, even if the new save can't be renamed over it.
+func backupFile(file, backup string) error {
+	if _, err := os.Stat(file); err != nil {
+		return err
+	}
+	os.Remove(backup) // links can't replace an existing file.
+	if err := os.Link(file, backup); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(backup, data, 0644)
+}
+
+// checkWritable records a save failure if the save directory can't be
+// written, ie: a read-only data directory, so that the player can be
+// warned at startup rather than after playing.
+func (s *Save) checkWritable() {
+	f, err := os.CreateTemp(path.Dir(s.file), "freecell-*.tmp")
+	if err == nil {
+		f.Close()
+		err = os.Remove(f.Name())
+	}
+	s.saveFailed(err)
+}
+
+// saveFailed records the result of saving. The game keeps running with
+// its state in memory, so the first failure is logged as an error and
+// a later successful save clears it.
+func (s *Save) saveFailed(err error) {
+	if err != nil && s.saveErr == nil {
+		slog.Error("progress is not being saved", "file", s.file, "error", err)
+	}
+	s.saveErr = err
 }
 
 // restore reads persisted information from disk.
 // It handles the case where a previous restore file doesn't exist.
+// The backup save file is used if the save file is missing or can't
+// be read.
 func (s *Save) restore() {
-	if dbytes, err := os.ReadFile(s.file); err == nil {
-		if err = yaml.Unmarshal(dbytes, s); err != nil {
-			slog.Debug("restore game state", "error", err)
+	if err := s.load(s.file); err != nil {
+		slog.Debug("restore game state", "error", err)
+		if err = s.load(s.file + ".bak"); err != nil {
+			if !os.IsNotExist(err) {
+				slog.Debug("restore game backup", "error", err)
+			}
+			return // first launch if neither file exists.
 		}
+		slog.Info("restored game state from backup")
+	}
+}
+
+// load reads the given save file into the save data.
+func (s *Save) load(file string) error {
+	dbytes, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return s.decode(dbytes)
+}
+
+// compactSaveHeader starts binary save files. It can't start a yaml file.
+// The save fields that the file was written with come before the save data.
+var compactSaveHeader = []byte("\x00pfc1")
+
+// saveFields returns the names of the persisted save fields.
+func saveFields() (fields []string) {
+	st := reflect.TypeOf(Save{})
+	for i := range st.NumField() {
+		if st.Field(i).IsExported() {
+			fields = append(fields, st.Field(i).Name)
+		}
+	}
+	return fields
+}
+
+// encode returns the save data as yaml, or as gob binary data
+// after the compact save header if CompactSave is enabled.
+// yaml is the default since it can be read and edited for debugging.
+func (s *Save) encode() ([]byte, error) {
+	if !s.CompactSave {
+		return yaml.Marshal(s)
+	}
+	buf := bytes.NewBuffer(slices.Clone(compactSaveHeader))
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(saveFields()); err != nil {
+		return nil, err
 	}
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode reads save data in either format, detected by the
+// compact save header.
+func (s *Save) decode(data []byte) error {
+	if !bytes.HasPrefix(data, compactSaveHeader) {
+		return yaml.Unmarshal(data, s)
+	}
+
+	// gob skips zero values, so decode into empty save data rather
+	// than over the defaults, ie: a preference that was turned off.
+	d := Save{file: s.file, unsavedPlay: s.unsavedPlay, saveErr: s.saveErr}
+	dec := gob.NewDecoder(bytes.NewReader(data[len(compactSaveHeader):]))
+	var written []string // fields in the save file.
+	if err := dec.Decode(&written); err != nil {
+		return err
+	}
+	if err := dec.Decode(&d); err != nil {
+		return err
+	}
+
+	// fields added after the file was written keep their current values,
+	// ie: the defaults for new preferences, as they do for yaml files.
+	dv, sv := reflect.ValueOf(&d).Elem(), reflect.ValueOf(s).Elem()
+	for i := range dv.NumField() {
+		if field := dv.Type().Field(i); field.IsExported() && !slices.Contains(written, field.Name) {
+			dv.Field(i).Set(sv.Field(i))
+		}
+	}
+
+	// gob also skips empty maps.
+	if d.Scores == nil {
+		d.Scores = map[uint]uint{}
+	}
+	if d.VariantScores == nil {
+		d.VariantScores = map[string]map[uint]uint{}
+	}
+	if d.Attempts == nil {
+		d.Attempts = map[uint]uint{}
+	}
+	if d.BestTimes == nil {
+		d.BestTimes = map[uint]time.Duration{}
+	}
+	if d.WinDates == nil {
+		d.WinDates = map[uint]time.Time{}
+	}
+	if d.AttemptsToWin == nil {
+		d.AttemptsToWin = map[uint]uint{}
+	}
+	if d.SolverMoves == nil {
+		d.SolverMoves = map[uint]int{}
+	}
+	if d.Bookmarks == nil {
+		d.Bookmarks = map[string]Bookmark{}
+	}
+	*s = d
+	return nil
+}
+
+// layoutImportFile is the data directory file with a layout to play.
+const layoutImportFile = "freecell-layout.txt"
+
+// importLayout reads the layout that the player pasted into the data
+// directory import file. Returns the file name and the layout.
+func (s *Save) importLayout() (file, layout string, err error) {
+	file = path.Join(path.Dir(s.file), layoutImportFile)
+	data, err := os.ReadFile(file)
+	return file, string(data), err
 }
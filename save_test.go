@@ -0,0 +1,530 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// go test -run Corrupt
+func TestRestoreCorruptSave(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	s.Scores[42] = 99
+	s.persistSeed(42)
+	s.persistSeed(43) // previous save becomes the backup.
+
+	// simulate a partially written save file.
+	if err := os.WriteFile(s.file, []byte("seed: [43\nscores: {"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	if restored.Seed != 42 || restored.Scores[42] != 99 {
+		t.Errorf("expected backup seed 42 score 99, got seed %d score %d", restored.Seed, restored.Scores[42])
+	}
+}
+
+// go test -run MissingSave
+func TestRestoreMissingSave(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	s.Scores[42] = 99
+	s.persistSeed(42)
+	s.persistSeed(43) // the save is backed up without moving it.
+	if _, err := os.Stat(s.file); err != nil {
+		t.Fatalf("expected the save file to remain: %v", err)
+	}
+
+	// a save lost between writes is restored from the backup.
+	if err := os.Remove(s.file); err != nil {
+		t.Fatal(err)
+	}
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	if restored.Seed != 42 || restored.Scores[42] != 99 {
+		t.Errorf("expected backup seed 42 score 99, got seed %d score %d", restored.Seed, restored.Scores[42])
+	}
+
+	// a first launch has neither file.
+	first := newSave(t.TempDir(), "freecell.save")
+	first.restore()
+	if first.Seed != 1 || len(first.Scores) != 0 {
+		t.Errorf("expected a new save got seed %d scores %v", first.Seed, first.Scores)
+	}
+}
+
+// go test -run Classic
+func TestImportClassicScores(t *testing.T) {
+	s := newSave(t.TempDir(), "freecell.save")
+	s.Scores[2] = 80
+	s.Scores[31_999] = 120
+	classic := map[uint]uint{
+		1:      90,  // new score.
+		2:      95,  // keep the existing better score.
+		11_982: 100, // new score.
+		31_999: 110, // better than existing score.
+		0:      50,  // invalid classic game number.
+		32_001: 50,  // invalid classic game number.
+	}
+	if err := s.ImportClassicScores(classic); err == nil {
+		t.Errorf("expected error for invalid classic games")
+	}
+	want := map[uint]uint{1: 90, 2: 80, 11_982: 100, 31_999: 110}
+	if len(s.Scores) != len(want) {
+		t.Errorf("expected %d scores got %d", len(want), len(s.Scores))
+	}
+	for seed, score := range want {
+		if s.Scores[seed] != score {
+			t.Errorf("seed %d expected score %d got %d", seed, score, s.Scores[seed])
+		}
+	}
+
+	// classic game numbers deal the same cards as the published games.
+	for game, cards := range games {
+		seed, ok := classicSeed(game)
+		if !ok {
+			continue // not a classic game.
+		}
+		deal := shuffle(seed, deck)
+		for i := range cards {
+			if cards[i] != deal[i].Sym {
+				t.Fatalf("classic game %d card %d expected %s got %s", game, i, cards[i], deal[i].Sym)
+			}
+		}
+	}
+}
+
+// go test -run ResetProgress
+func TestResetProgress(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	s.Scores[42] = 99
+	s.recordWin()
+	s.persistWindow(10, 20, 800, 900)
+	s.persistSeed(42)
+	s.resetProgress()
+
+	// the reset is persisted.
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	if len(restored.Scores) != 0 || restored.Stats != (Stats{}) {
+		t.Errorf("expected no scores or stats got %v %+v", restored.Scores, restored.Stats)
+	}
+	d := restored.Display
+	if restored.Seed != 42 || d.Wx != 10 || d.Wy != 20 || d.Ww != 800 || d.Wh != 900 {
+		t.Errorf("expected seed and window to be kept, got %d %+v", restored.Seed, d)
+	}
+
+	// two confirmations are needed.
+	for presses, want := range []bool{false, false, false, true, true} {
+		if _, reset := resetPrompt(presses); reset != want {
+			t.Errorf("presses %d: expected reset %t", presses, want)
+		}
+	}
+}
+
+// go test -run DataDir
+func TestDataDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	t.Setenv(dataDirEnv, dir)
+	if got := dataDir(); got != dir {
+		t.Errorf("expected override %s got %s", dir, got)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expected override to be created: %s", err)
+	}
+
+	// directories that can't be created are ignored.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(dataDirEnv, filepath.Join(file, "data"))
+	if got := dataDir(); got != saveDir() {
+		t.Errorf("expected default %s got %s", saveDir(), got)
+	}
+	t.Setenv(dataDirEnv, "")
+	if got := dataDir(); got != saveDir() {
+		t.Errorf("expected default %s got %s", saveDir(), got)
+	}
+}
+
+// go test -run HardestUnwon
+func TestHardestUnwon(t *testing.T) {
+	s := newSave(t.TempDir(), "freecell.save")
+	if _, ok := s.hardestUnwon(); ok {
+		t.Errorf("expected no unwon games")
+	}
+	s.Attempts = map[uint]uint{10: 3, 20: 7, 30: 5, 40: 5, 50: 9}
+	s.Scores[50] = 100 // won, so not practiced.
+	if seed, ok := s.hardestUnwon(); !ok || seed != 20 {
+		t.Errorf("expected seed 20 got %d %t", seed, ok)
+	}
+	s.Scores[20] = 100
+	if seed, ok := s.hardestUnwon(); !ok || seed != 30 {
+		t.Errorf("expected the lower tied seed 30 got %d %t", seed, ok)
+	}
+
+	// leaving a game records an attempt.
+	s.recordLoss(10)
+	s.recordLoss(10)
+	s.recordLoss(10)
+	if seed, ok := s.hardestUnwon(); !ok || seed != 10 || s.Attempts[10] != 6 {
+		t.Errorf("expected seed 10 with 6 attempts got %d %d", seed, s.Attempts[10])
+	}
+}
+
+// go test -run Bookmark
+func TestBookmark(t *testing.T) {
+	dir := t.TempDir()
+	l := &logic{}
+	l.NewGame(1)
+	for _, mv := range seed1Script[:5] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+	l.Undo()
+	l.Interact(seed1Script[4][0])
+	l.Interact(seed1Script[4][1])
+	l.AutoMoveAll()
+	s := newSave(dir, "freecell.save")
+	name := bookmarkName(1, l.MoveNumber())
+	s.persistBookmark(name, l.Bookmark())
+	s.persistBookmark(bookmarkName(1, 2), Bookmark{Seed: 1, Moves: l.moves.stack[:3]}) // an older bookmark.
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	if got, ok := restored.furthestBookmark(1); !ok || got != name {
+		t.Fatalf("expected furthest bookmark %s got %s", name, got)
+	}
+	if _, ok := restored.furthestBookmark(2); ok {
+		t.Errorf("expected no bookmarks for game 2")
+	}
+
+	// the restored position can be undone back to the deal.
+	other := &logic{}
+	other.NewGame(2)
+	if err := other.RestoreBookmark(restored.Bookmarks[name]); err != nil {
+		t.Fatal(err)
+	}
+	if other.Board() != l.Board() || other.MoveNumber() != l.MoveNumber() || other.gameSeed != 1 {
+		t.Errorf("expected move %d position got move %d", l.MoveNumber(), other.MoveNumber())
+	}
+	if other.MoveCount() != l.MoveCount() || other.moves.undos != 1 {
+		t.Errorf("expected move count %d with the undo got %d", l.MoveCount(), other.MoveCount())
+	}
+	if len(restored.Bookmarks[name].Moves) != 0 || len(restored.Bookmarks[name].History) != l.MoveNumber() {
+		t.Errorf("expected a bookmark of the moves, not the boards")
+	}
+	for other.MoveNumber() > 0 {
+		before := other.MoveNumber()
+		other.Undo()
+		if other.Board() != l.moves.stack[before-1] {
+			t.Fatalf("undo from move %d did not match the played move", before)
+		}
+	}
+
+	// older bookmarks are still restored.
+	if err := other.RestoreBookmark(restored.Bookmarks[bookmarkName(1, 2)]); err != nil || other.Board() != l.moves.stack[2] {
+		t.Errorf("expected the older bookmark at move 2 got %v", err)
+	}
+
+	// bookmarks for a different deal are rejected.
+	bad := restored.Bookmarks[name]
+	bad.Seed = 2
+	if err := other.RestoreBookmark(bad); err == nil {
+		t.Errorf("expected an error for a mismatched deal")
+	}
+	old := restored.Bookmarks[bookmarkName(1, 2)]
+	old.Seed = 2
+	if err := other.RestoreBookmark(old); err == nil {
+		t.Errorf("expected an error for a mismatched older bookmark")
+	}
+}
+
+// go test -run ExportStats
+func TestExportStats(t *testing.T) {
+	s := newSave(t.TempDir(), "freecell.save")
+	s.Scores[42] = 99
+	s.recordTime(42, 95*time.Second)
+	s.recordTime(42, 120*time.Second) // slower times are ignored.
+	s.Scores[7] = 120
+	s.Attempts[7] = 2
+	s.Attempts[500] = 3
+	var buf bytes.Buffer
+	if err := writeStatsCSV(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	want := "seed,won,best_moves,best_seconds,attempts\n" +
+		"7,true,120,,2\n" +
+		"42,true,99,95.0,0\n" +
+		"500,false,,,3\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	// the export is written to the data directory.
+	file, err := s.exportStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || !strings.HasPrefix(string(data), "seed,won") || filepath.Dir(file) != filepath.Dir(s.file) {
+		t.Errorf("expected the export in the data directory got %s %s", file, err)
+	}
+}
+
+// go test -run CompactSave
+func TestCompactSave(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	s.Scores[42] = 99
+	s.recordWin()
+	s.recordTime(42, 95*time.Second)
+	s.recordSolverMoves(variantClassic, 42, 90)
+	s.recordLoss(7)
+	s.persistWindow(10, 20, 800, 900)
+	s.toggleFavorite(42)
+	s.persistSeed(7)
+	s.persistBookmark(bookmarkName(7, 0), Bookmark{Seed: 7, Moves: [][52]uint{{1, 2, 3}}})
+	s.UndoToast, s.AutoMoveDelay = false, 0 // zero values replace the defaults.
+	s.AutoPlay = autoPlayOnDemand
+	s.CompactSave = true
+	s.persist()
+	data, err := os.ReadFile(s.file)
+	if err != nil || !bytes.HasPrefix(data, compactSaveHeader) {
+		t.Fatalf("expected a compact save file %s", err)
+	}
+
+	// the format is detected when restoring.
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	if !reflect.DeepEqual(restored, s) {
+		t.Errorf("expected\n%+v\ngot\n%+v", s, restored)
+	}
+
+	// turning compact saves off goes back to yaml.
+	restored.CompactSave = false
+	restored.persist()
+	again := newSave(dir, "freecell.save")
+	again.restore()
+	if !reflect.DeepEqual(again, restored) {
+		t.Errorf("expected\n%+v\ngot\n%+v", restored, again)
+	}
+
+	// empty maps are restored as empty, not nil.
+	empty := newSave(t.TempDir(), "freecell.save")
+	empty.CompactSave = true
+	empty.persist()
+	empty.Scores = nil
+	empty.restore()
+	if empty.Scores == nil || empty.Bookmarks == nil || empty.BestTimes == nil {
+		t.Errorf("expected empty maps after restoring")
+	}
+}
+
+// go test -run CompactDefaults
+func TestCompactDefaults(t *testing.T) {
+	restore := func(values ...any) *Save {
+		t.Helper()
+		buf := bytes.NewBuffer(slices.Clone(compactSaveHeader))
+		enc := gob.NewEncoder(buf)
+		for _, v := range values {
+			if err := enc.Encode(v); err != nil {
+				t.Fatal(err)
+			}
+		}
+		s := newSave(t.TempDir(), "freecell.save")
+		if err := os.WriteFile(s.file, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		s.restore()
+		return s
+	}
+
+	// files list their fields, so that preferences turned off are kept
+	// and preferences added after the file was written keep their defaults.
+	saved := newSave(t.TempDir(), "freecell.save")
+	saved.Seed, saved.UndoToast, saved.AutoMoveDelay = 7, false, 0
+	fields := slices.DeleteFunc(saveFields(), func(f string) bool { return f == "AutoMoveDelay" })
+	s := restore(fields, saved)
+	if s.Seed != 7 || s.UndoToast || s.AutoMoveDelay != -1 {
+		t.Errorf("expected seed 7, no undo toast, and the auto move default got %d %t %d", s.Seed, s.UndoToast, s.AutoMoveDelay)
+	}
+}
+
+// go test -run VariantScores
+func TestVariantScores(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	for i, variant := range variants {
+		s.setVariant(variant)
+		if s.variant() != variant {
+			t.Fatalf("expected variant %s got %s", variant, s.variant())
+		}
+		s.scores()[42] = uint(90 + i)
+	}
+	s.persist()
+
+	// each variant keeps its own score for the same seed.
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	for i, variant := range variants {
+		restored.setVariant(variant)
+		if got := restored.scores()[42]; got != uint(90+i) {
+			t.Errorf("%s: expected score %d got %d", variant, 90+i, got)
+		}
+	}
+	if restored.Scores[42] != 90 {
+		t.Errorf("expected classic scores in the original scores got %d", restored.Scores[42])
+	}
+
+	// each variant keeps its own per seed records.
+	for i, variant := range variants {
+		restored.setVariant(variant)
+		restored.recordTime(42, time.Duration(60+i)*time.Second)
+		restored.recordWinDate(42, time.Unix(int64(i+1), 0))
+		restored.recordSolverMoves(variant, 42, 80+i)
+		for range i + 1 {
+			restored.recordLoss(42)
+		}
+		restored.RepeatUntilWon = true
+		restored.recordAttemptsToWin(7)
+	}
+	for i, variant := range variants {
+		restored.setVariant(variant)
+		if got := restored.bestTimes()[42]; got != time.Duration(60+i)*time.Second {
+			t.Errorf("%s: expected time %ds got %s", variant, 60+i, got)
+		}
+		if got := restored.winDates()[42]; got.Unix() != int64(i+1) {
+			t.Errorf("%s: expected win date %d got %v", variant, i+1, got)
+		}
+		if got := restored.solverMoves(variant)[42]; got != 80+i {
+			t.Errorf("%s: expected solver moves %d got %d", variant, 80+i, got)
+		}
+		if got := restored.attempt(42); got != uint(i+2) {
+			t.Errorf("%s: expected attempt %d got %d", variant, i+2, got)
+		}
+		if got := restored.attemptsToWin()[7]; got != 1 {
+			t.Errorf("%s: expected a first attempt win got %d", variant, got)
+		}
+	}
+	if restored.BestTimes[42] != 60*time.Second || restored.Attempts[42] != 1 || restored.SolverMoves[42] != 80 {
+		t.Errorf("expected classic records in the original maps")
+	}
+
+	// the single card moves preference predates variants.
+	old := newSave(t.TempDir(), "freecell.save")
+	old.SingleCardMoves = true
+	if old.variant() != variantSingleCard {
+		t.Errorf("expected the single card variant got %s", old.variant())
+	}
+}
+
+// go test -run SaveFailure
+func TestSaveFailure(t *testing.T) {
+	var log bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelError})))
+
+	// a file in place of the save directory can't be written.
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := newSave(dir, "freecell.save")
+	s.file = filepath.Join(blocked, "freecell.save")
+	s.checkWritable()
+	if s.saveErr == nil || !strings.Contains(log.String(), "not being saved") {
+		t.Fatalf("expected the unwritable directory to be reported: %q", log.String())
+	}
+
+	// play continues in memory and the failure is only logged once.
+	log.Reset()
+	s.persistSeed(42)
+	if s.saveErr == nil || s.Seed != 42 || log.Len() != 0 {
+		t.Errorf("expected a quiet failed save got %v %d %q", s.saveErr, s.Seed, log.String())
+	}
+
+	// saving again clears the failure.
+	s.file = filepath.Join(dir, "freecell.save")
+	s.persist()
+	if s.saveErr != nil {
+		t.Errorf("expected the save to work: %s", s.saveErr)
+	}
+}
+
+// go test -run ResetWindow
+func TestResetWindow(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	s.persistWindow(-3000, 2500, 640, 480) // ie: a disconnected monitor.
+	s.resetWindow()
+
+	restored := newSave(dir, "freecell.save")
+	restored.restore()
+	x, y, w, h := defaultSize()
+	if d := restored.Display; d.Wx != x || d.Wy != y || d.Ww != w || d.Wh != h {
+		t.Errorf("expected the default window %d %d %d %d got %+v", x, y, w, h, d)
+	}
+}
+
+// go test -run InProgress
+func TestInProgress(t *testing.T) {
+	dir := t.TempDir()
+	s := newSave(dir, "freecell.save")
+	saved := func() bool {
+		restored := newSave(dir, "freecell.save")
+		restored.restore()
+		return restored.InProgress
+	}
+	if s.InProgress {
+		t.Fatalf("expected no game in progress for a new save")
+	}
+
+	// a move starts the game and a win finishes it.
+	s.persistInProgress(true)
+	if !s.InProgress || !saved() {
+		t.Errorf("expected a saved game in progress after a move")
+	}
+	s.recordWin()
+	s.persist()
+	if s.InProgress || saved() {
+		t.Errorf("expected no game in progress after a win")
+	}
+
+	// a new game or reset also finishes it.
+	s.persistInProgress(true)
+	s.persistInProgress(false)
+	if s.InProgress || saved() {
+		t.Errorf("expected no game in progress after a reset")
+	}
+
+	// saves from before the flag have no game in progress.
+	data, err := yaml.Marshal(map[string]uint{"seed": 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "old.save"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := newSave(dir, "old.save")
+	old.restore()
+	if old.Seed != 42 || old.InProgress {
+		t.Errorf("expected an old save to load without a game in progress")
+	}
+}
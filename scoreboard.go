@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// scoreboard.go lists the completed games so that the best scores can
+// be browsed by move count, time, or date, and replayed.
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// scoreboardRows is the number of games shown at once in the scoreboard.
+const scoreboardRows = 8
+
+// Scoreboard sort orders, switched with the S key.
+const (
+	scoreSortMoves = "moves" // fewest moves first.
+	scoreSortTime  = "time"  // fastest first.
+	scoreSortDate  = "date"  // most recently won first.
+)
+
+// scoreboard sort orders in the order they are switched.
+var scoreSorts = []string{scoreSortMoves, scoreSortTime, scoreSortDate}
+
+// scoreEntry is a completed game.
+type scoreEntry struct {
+	seed  uint          // game number.
+	moves uint          // best score.
+	clock time.Duration // fastest game clock, zero if not recorded.
+	won   time.Time     // most recent win, zero if not recorded.
+}
+
+// scoreEntries returns the completed games in seed order.
+func scoreEntries(scores map[uint]uint, times map[uint]time.Duration, dates map[uint]time.Time) (entries []scoreEntry) {
+	for seed, moves := range scores {
+		entries = append(entries, scoreEntry{seed: seed, moves: moves, clock: times[seed], won: dates[seed]})
+	}
+	slices.SortFunc(entries, func(a, b scoreEntry) int { return cmp.Compare(a.seed, b.seed) })
+	return entries
+}
+
+// byMoves orders games by fewest moves, then by seed.
+func byMoves(a, b scoreEntry) int {
+	return cmp.Or(cmp.Compare(a.moves, b.moves), cmp.Compare(a.seed, b.seed))
+}
+
+// byTime orders games by fastest clock, then by seed.
+// Games without a recorded clock are last.
+func byTime(a, b scoreEntry) int {
+	return cmp.Or(missingLast(a.clock == 0, b.clock == 0), cmp.Compare(a.clock, b.clock), cmp.Compare(a.seed, b.seed))
+}
+
+// byDate orders games by most recent win, then by seed.
+// Games without a recorded win date are last.
+func byDate(a, b scoreEntry) int {
+	return cmp.Or(missingLast(a.won.IsZero(), b.won.IsZero()), b.won.Compare(a.won), cmp.Compare(a.seed, b.seed))
+}
+
+// missingLast orders games with a missing value after the others.
+func missingLast(aMissing, bMissing bool) int {
+	switch {
+	case aMissing == bMissing:
+		return 0
+	case aMissing:
+		return 1
+	}
+	return -1
+}
+
+// scoreOrder returns the comparator for the given sort order.
+func scoreOrder(order string) func(a, b scoreEntry) int {
+	switch order {
+	case scoreSortTime:
+		return byTime
+	case scoreSortDate:
+		return byDate
+	}
+	return byMoves
+}
+
+// beatPar returns true if the game was won in fewer moves than par.
+func beatPar(e scoreEntry) bool {
+	par, ok := parScore(e.seed)
+	return ok && e.moves < par
+}
+
+// sortScores returns the games in the given order, keeping only the
+// games that beat par if parOnly is set.
+func sortScores(entries []scoreEntry, order string, parOnly bool) []scoreEntry {
+	sorted := slices.Clone(entries)
+	if parOnly {
+		sorted = slices.DeleteFunc(sorted, func(e scoreEntry) bool { return !beatPar(e) })
+	}
+	slices.SortStableFunc(sorted, scoreOrder(order))
+	return sorted
+}
+
+// scoreList is the scoreboard list of completed games.
+type scoreList struct {
+	entries []scoreEntry // all completed games.
+	order   string       // scoreSorts order.
+	parOnly bool         // true to only list games that beat par.
+	shown   []scoreEntry // sorted and filtered games.
+	focus   int          // index of the highlighted game.
+}
+
+// newScoreList creates a scoreboard sorted by moves and focused
+// on the first game.
+func newScoreList(entries []scoreEntry) *scoreList {
+	sl := &scoreList{entries: entries, order: scoreSortMoves}
+	sl.update()
+	return sl
+}
+
+// update sorts and filters the games, focusing on the first game.
+func (sl *scoreList) update() {
+	sl.shown = sortScores(sl.entries, sl.order, sl.parOnly)
+	sl.focus = 0
+}
+
+// apply handles a scoreboard intent. Switching the sort order or the
+// par filter moves the focus to the top. Choosing the focused game also
+// closes the scoreboard.
+func (sl *scoreList) apply(it intent) (seed uint, chosen, closed bool) {
+	switch it {
+	case focusUpIntent:
+		sl.focus = max(0, sl.focus-1)
+	case focusDownIntent:
+		sl.focus = max(0, min(sl.focus+1, len(sl.shown)-1))
+	case sortIntent:
+		sl.order = scoreSorts[(slices.Index(scoreSorts, sl.order)+1)%len(scoreSorts)]
+		sl.update()
+	case parIntent:
+		sl.parOnly = !sl.parOnly
+		sl.update()
+	case chooseIntent:
+		if len(sl.shown) > 0 {
+			return sl.shown[sl.focus].seed, true, true
+		}
+		return 0, false, true
+	case closeIntent:
+		return 0, false, true
+	}
+	return 0, false, false
+}
+
+// lines returns a heading followed by the text for up to rows games,
+// scrolled so the focused game is visible.
+func (sl *scoreList) lines(rows int) []string {
+	heading := "by " + sl.order
+	if sl.parOnly {
+		heading += ", under par"
+	}
+	lines := []string{heading}
+	if len(sl.shown) == 0 {
+		return append(lines, "no games")
+	}
+	start := max(0, min(sl.focus-rows/2, len(sl.shown)-rows))
+	for i := start; i < len(sl.shown) && i < start+rows; i++ {
+		e := sl.shown[i]
+		focus, clock, won := " ", "  --:--", "----------"
+		if i == sl.focus {
+			focus = ">"
+		}
+		if e.clock > 0 {
+			secs := int(e.clock.Seconds())
+			clock = fmt.Sprintf("%4d:%02d", secs/60, secs%60)
+		}
+		if !e.won.IsZero() {
+			won = e.won.Format(time.DateOnly)
+		}
+		lines = append(lines, fmt.Sprintf("%s%06d %03d %s %s", focus, e.seed, e.moves, clock, won))
+	}
+	return lines
+}
+
+// openScoreboard lists the completed games, pausing the game until the
+// list is closed.
+func (gm *game) openScoreboard() {
+	if gm.anim != nil || gm.state != PlayState {
+		return
+	}
+	gm.scoreList = newScoreList(scoreEntries(gm.save.scores(), gm.save.bestTimes(), gm.save.winDates()))
+	gm.state = ScoreState
+	gm.writeScoreboard()
+}
+
+// runScoreboard handles the key presses while the scoreboard is open.
+// Choosing a game changes to that game unless the seed is fixed.
+// Leaving a game in progress keeps the scoreboard open until the choice
+// is confirmed.
+func (gm *game) runScoreboard(pressed map[int32]bool) {
+	intents := listIntents(pressed, scoreboardKeys)
+	if len(intents) == 0 {
+		return
+	}
+	for _, it := range intents {
+		seed, chosen, closed := gm.scoreList.apply(it)
+		if chosen && seed != gm.save.Seed && !gm.save.seedFixed() {
+			if !gm.confirmNewGame(time.Now()) {
+				continue // stay in the scoreboard for a second enter.
+			}
+			gm.save.persistSeed(seed)
+			gm.resetBoard()
+		}
+		if closed {
+			gm.closeScoreboard()
+			return
+		}
+	}
+	gm.writeScoreboard()
+}
+
+// writeScoreboard shows the current scoreboard.
+func (gm *game) writeScoreboard() {
+	if err := gm.scoreboard.write(gm.eng, gm.scoreList.lines(scoreboardRows)...); err != nil {
+		slog.Debug("scoreboard text", "err", err)
+	}
+	gm.scoreboard.show(true)
+}
+
+// closeScoreboard hides the scoreboard and resumes play.
+func (gm *game) closeScoreboard() {
+	gm.scoreboard.show(false)
+	gm.scoreList = nil
+	gm.state = PlayState
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// seeds returns the game numbers of the given games.
+func seeds(entries []scoreEntry) (seeds []uint) {
+	for _, e := range entries {
+		seeds = append(seeds, e.seed)
+	}
+	return seeds
+}
+
+// go test -run ScoreSort
+func TestScoreSort(t *testing.T) {
+	day := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := scoreEntries(
+		map[uint]uint{1: 98, 3: 90, 7: 95, 42: 120, 99: 90},
+		map[uint]time.Duration{1: 3 * time.Minute, 3: 5 * time.Minute, 42: 2 * time.Minute, 99: 3 * time.Minute},
+		map[uint]time.Time{1: day, 3: day.Add(48 * time.Hour), 7: day.Add(24 * time.Hour)},
+	)
+	if got := seeds(entries); !slices.Equal(got, []uint{1, 3, 7, 42, 99}) {
+		t.Fatalf("expected the games in seed order got %v", got)
+	}
+
+	// ties are broken by seed and missing values are last.
+	for order, want := range map[string][]uint{
+		scoreSortMoves: {3, 99, 7, 1, 42},
+		scoreSortTime:  {42, 1, 99, 3, 7},
+		scoreSortDate:  {3, 7, 1, 42, 99},
+	} {
+		if got := seeds(sortScores(entries, order, false)); !slices.Equal(got, want) {
+			t.Errorf("by %s: expected %v got %v", order, want, got)
+		}
+	}
+	if byMoves(entries[1], entries[4]) >= 0 || byMoves(entries[4], entries[1]) <= 0 || byMoves(entries[0], entries[0]) != 0 {
+		t.Errorf("expected equal moves to be ordered by seed")
+	}
+
+	// only games won in fewer moves than par pass the par filter.
+	par1, _ := parScore(1)
+	for _, tt := range []struct {
+		e    scoreEntry
+		beat bool
+	}{
+		{scoreEntry{seed: 1, moves: par1 - 1}, true},
+		{scoreEntry{seed: 1, moves: par1}, false},
+		{scoreEntry{seed: 5, moves: 1}, false}, // no par.
+	} {
+		if beatPar(tt.e) != tt.beat {
+			t.Errorf("seed %d moves %d: expected beat par %t", tt.e.seed, tt.e.moves, tt.beat)
+		}
+	}
+	if got := seeds(sortScores(entries, scoreSortMoves, true)); !slices.Equal(got, []uint{1}) {
+		t.Errorf("expected only game 1 under par got %v", got)
+	}
+}
+
+// go test -run ScoreList
+func TestScoreList(t *testing.T) {
+	entries := scoreEntries(map[uint]uint{1: 99, 2: 85, 3: 90}, nil, nil)
+	sl := newScoreList(entries)
+	if lines := sl.lines(scoreboardRows); len(lines) != 4 || lines[0] != "by moves" || lines[1] != ">000002 085   --:-- ----------" {
+		t.Fatalf("unexpected scoreboard %q", lines)
+	}
+	sl.apply(focusDownIntent)
+	sl.apply(sortIntent) // sort by time starts at the top.
+	if seed, chosen, closed := sl.apply(chooseIntent); seed != 1 || !chosen || !closed {
+		t.Errorf("expected to choose 1 got %d %t %t", seed, chosen, closed)
+	}
+	sl.apply(parIntent) // nothing under par.
+	if lines := sl.lines(scoreboardRows); len(lines) != 2 || lines[0] != "by time, under par" {
+		t.Errorf("unexpected filtered scoreboard %q", lines)
+	}
+	if _, chosen, closed := sl.apply(chooseIntent); chosen || !closed {
+		t.Errorf("expected an empty scoreboard to close without choosing")
+	}
+}
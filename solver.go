@@ -0,0 +1,223 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// solver.go searches for short solutions in order to grade how
+// efficiently the player won a game.
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+)
+
+// solveBudget limits the time spent searching for a solution.
+// Most deals are solved well within the budget.
+const solveBudget = 2 * time.Second
+
+// solveWeight trades solution length for search speed. The estimate of
+// the remaining work is weighted so that the search heads for the
+// foundations.
+const solveWeight = 2
+
+// efficiencyGrade describes how the player's winning move count compares
+// to the solver solution length, ie: "12% over solver".
+func efficiencyGrade(player, solver int) string {
+	switch {
+	case solver <= 0:
+		return "" // no solution to compare.
+	case player < solver:
+		return fmt.Sprintf("beat solver by %d", solver-player)
+	case player == solver:
+		return "matched the solver"
+	}
+	return fmt.Sprintf("%d%% over solver", (player-solver)*100/solver)
+}
+
+// solveResult is the outcome of a background solver search.
+type solveResult struct {
+	seed    uint   // game that was solved.
+	variant string // rules the game was solved under.
+	length  int    // solution moves.
+	ok      bool   // false if the search ran out of time.
+}
+
+// undoCheckResult is the outcome of a background undo check.
+type undoCheckResult struct {
+	board    [52]uint // board position that was checked.
+	helps    bool     // true if undoing the last move is better.
+	computed bool     // false if the solver couldn't tell.
+}
+
+// solveLength returns the number of moves in a solution from the given
+// board. Auto moves to the foundations count as moves, the same as the
+// game score. The search is a weighted best first search, so the
+// solution is short but not always the shortest.
+// Returns false if no solution is found within the time budget.
+func solveLength(board [52]uint, singleCardMoves bool, budget time.Duration) (length int, ok bool) {
+	length, ok, _ = solveSearch(board, singleCardMoves, budget)
+	return length, ok
+}
+
+// solveSearch is solveLength that also returns true if the search
+// finished within the time budget, ie: a finished search that is not
+// ok shows that the board has no solution.
+func solveSearch(board [52]uint, singleCardMoves bool, budget time.Duration) (length int, ok, finished bool) {
+	start := time.Now()
+	best := map[[52]uint]int{board: 0}
+	open := &solveQueue{{board: board}}
+	for open.Len() > 0 && time.Since(start) < budget {
+		n := heap.Pop(open).(solveNode)
+		if n.moves > best[n.board] {
+			continue // already reached in fewer moves.
+		}
+		l := &logic{board: n.board, singleCardMoves: singleCardMoves, moves: &moves{}}
+		if l.IsGameWon() {
+			return n.moves, true, true
+		}
+		for _, mv := range solveMoves(l) {
+			next := &logic{board: n.board, singleCardMoves: singleCardMoves, moves: &moves{}}
+			next.moves.record(n.board)
+			next.Interact(mv[0])
+			if !next.Interact(mv[1]) {
+				continue
+			}
+			next.AutoMoveAll()
+			cost := n.moves + len(next.moves.stack) - 1
+			if prev, seen := best[next.board]; seen && prev <= cost {
+				continue
+			}
+			best[next.board] = cost
+			heap.Push(open, solveNode{board: next.board, moves: cost, score: cost + solveWeight*remaining(next)})
+		}
+	}
+	return 0, false, open.Len() == 0
+}
+
+// undoCheckBudget limits the time spent on each of the two searches
+// used to check if the last move should be undone.
+const undoCheckBudget = 150 * time.Millisecond
+
+// undoHelpMargin is how many more moves the solution from the current
+// board must need before an undo is suggested. The solver solutions
+// are short but not the shortest, so small differences are noise.
+const undoHelpMargin = 5
+
+// UndoWouldHelp checks if undoing the last move opens a better line:
+// the board before the move has a solution and the current board has
+// none, or needs clearly more moves. The undo itself is counted.
+// Only meant to be called when the player asks since it runs the
+// solver twice. Returns computed false if the solver ran out of time
+// or can't play the current rules, ie: with closed free cells.
+func (l *logic) UndoWouldHelp() (helps, computed bool) {
+	if l.closedCells > 0 {
+		return false, false // the solver plays with all the free cells.
+	}
+	if l.MoveNumber() == 0 {
+		return false, true // nothing to undo.
+	}
+	return undoWouldHelp(l.PreviousBoard(), l.board, l.singleCardMoves)
+}
+
+// undoWouldHelp compares the solutions from the boards before and
+// after the last move. It only uses its arguments so that it can be
+// run in the background.
+func undoWouldHelp(previous, board [52]uint, singleCardMoves bool) (helps, computed bool) {
+	before, ok, finished := solveSearch(previous, singleCardMoves, undoCheckBudget)
+	if !finished {
+		return false, false
+	}
+	if !ok {
+		return false, true // undoing doesn't reach a solution either.
+	}
+	after, ok, finished := solveSearch(board, singleCardMoves, undoCheckBudget)
+	switch {
+	case !finished:
+		return false, false
+	case !ok:
+		return true, true // the last move lost the game.
+	}
+	return after > before+1+undoHelpMargin, true
+}
+
+// solveMoves returns the legal moves worth searching. Only one empty
+// free cell and one empty cascade are tried since the others are the
+// same, and cards are not moved between free cells.
+func solveMoves(l *logic) (legal [][2]uint) {
+	places := []uint{}
+	cell, cascade := false, false
+	for pile := uint(0); pile < 16; pile++ {
+		switch {
+		case l.isFoundation(pile):
+			if top := l.cardAt(pile); top != NO_CARD {
+				places = append(places, top)
+			} else {
+				places = append(places, EMPTY_PILE1+pile)
+			}
+		case !l.emptyPile(pile):
+			if l.isCascade(pile) {
+				places = append(places, l.lastInCascade(pile-8).ID)
+			}
+		case l.isFreecell(pile) && !cell:
+			places, cell = append(places, EMPTY_PILE1+pile), true
+		case l.isCascade(pile) && !cascade:
+			places, cascade = append(places, EMPTY_PILE1+pile), true
+		}
+	}
+	for pick := AC; pick <= KS; pick++ {
+		if !l.CanMove(pick) {
+			continue
+		}
+		fromCell := l.isFreecell(l.board[pick])
+		for _, place := range places {
+			if fromCell && place >= EMPTY_PILE1 && l.isFreecell(place-EMPTY_PILE1) {
+				continue // moving between free cells doesn't help.
+			}
+			if l.canMoveTo(pick, place) {
+				legal = append(legal, [2]uint{pick, place})
+			}
+		}
+	}
+	return legal
+}
+
+// remaining estimates the work left: the cards that are not on the
+// foundations, plus the cascade cards that are on a lower card and so
+// have to be moved out of the way, plus the free cells in use.
+func remaining(l *logic) (cards int) {
+	for cid := AC; cid <= KS; cid++ {
+		bid := l.board[cid]
+		switch {
+		case l.isFreecell(bid):
+			cards += 2
+		case l.isCascade(bid):
+			cards++
+			if bid >= 16 && getCard(l.cardAt(bid-8)).Rank < getCard(cid).Rank {
+				cards++ // blocks a lower card.
+			}
+		}
+	}
+	return cards
+}
+
+// solveNode is a board position reached by the solver.
+type solveNode struct {
+	board [52]uint // position.
+	moves int      // moves from the start.
+	score int      // estimated solution length, lower is searched first.
+}
+
+// solveQueue orders the positions to search, implementing heap.Interface.
+type solveQueue []solveNode
+
+func (q solveQueue) Len() int           { return len(q) }
+func (q solveQueue) Less(i, j int) bool { return q[i].score < q[j].score }
+func (q solveQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *solveQueue) Push(x any)        { *q = append(*q, x.(solveNode)) }
+func (q *solveQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
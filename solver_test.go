@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// go test -run EfficiencyGrade
+func TestEfficiencyGrade(t *testing.T) {
+	for _, tc := range []struct {
+		player, solver int
+		want           string
+	}{
+		{100, 80, "25% over solver"},
+		{81, 80, "1% over solver"},
+		{80, 80, "matched the solver"},
+		{75, 80, "beat solver by 5"},
+		{90, 0, ""}, // the solver ran out of time.
+	} {
+		if got := efficiencyGrade(tc.player, tc.solver); got != tc.want {
+			t.Errorf("%d vs %d: expected %q got %q", tc.player, tc.solver, tc.want, got)
+		}
+		if len(efficiencyGrade(tc.player, tc.solver)) > 20 {
+			t.Errorf("%d vs %d: grade is too long for a toast", tc.player, tc.solver)
+		}
+	}
+}
+
+// go test -run SolveLength
+func TestSolveLength(t *testing.T) {
+	// an ordered board is solved by moving each card to the foundations.
+	if length, ok := solveLength(orderedBoard(), false, time.Second); !ok || length != 52 {
+		t.Errorf("expected 52 moves got %d %t", length, ok)
+	}
+
+	// a quick deal is solved, and its solution can't beat the moves
+	// needed to put each card on the foundations.
+	l := &logic{}
+	l.NewGame(3)
+	if length, ok := solveLength(l.Board(), false, 10*time.Second); !ok || length < 52 {
+		t.Errorf("expected a solution for game 3 got %d %t", length, ok)
+	}
+	if _, ok := solveLength(l.Board(), false, 0); ok {
+		t.Errorf("expected no solution without time to search")
+	}
+}
+
+// go test -run UndoWouldHelp
+func TestUndoWouldHelp(t *testing.T) {
+	l := &logic{}
+	l.NewGame(1)
+	if helps, computed := l.UndoWouldHelp(); helps || !computed {
+		t.Errorf("expected nothing to undo for a new deal")
+	}
+	for _, mv := range seed1Script[:40] {
+		l.Interact(mv[0])
+		l.Interact(mv[1])
+		l.AutoMoveAll()
+	}
+
+	// the next scripted move keeps the game winnable.
+	good := &logic{}
+	if err := good.LoadFromHistory(1, append(l.History(), seed1Script[40])); err != nil {
+		t.Fatal(err)
+	}
+	if helps, computed := good.UndoWouldHelp(); helps || !computed {
+		t.Errorf("expected no undo for a good move got %t %t", helps, computed)
+	}
+
+	// moving the king into the empty cascade loses the game.
+	l.Interact(KS)
+	if !l.Interact(EMPTY_PILE1 + 12) {
+		t.Fatalf("expected the king to move")
+	}
+	if _, ok, finished := solveSearch(l.Board(), false, time.Second); ok || !finished {
+		t.Fatalf("expected a lost game")
+	}
+	if helps, computed := l.UndoWouldHelp(); !helps || !computed {
+		t.Errorf("expected an undo for a losing move got %t %t", helps, computed)
+	}
+
+	// the game checks in the background rather than during the update.
+	gm := &game{logic: l}
+	gm.checkUndo()
+	checking := gm.undoChecked
+	if gm.checkUndo(); checking == nil || gm.undoChecked != checking {
+		t.Fatalf("expected one background check")
+	}
+	if result := <-checking; result.board != l.Board() || !result.helps || !result.computed {
+		t.Errorf("expected an undo for the checked board got %+v", result)
+	}
+
+	// the solver doesn't know about closed free cells.
+	l.closedCells = 1
+	if _, computed := l.UndoWouldHelp(); computed {
+		t.Errorf("expected no check with closed free cells")
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// text.go displays lines of UI text using updatable textures.
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/gazed/vu"
+)
+
+const (
+	// monospace font character size in pixels at a pixel density of 1.
+	txtCharWidth  = 30
+	txtLineHeight = 56
+)
+
+// textLabel is an updatable 2D text display. The text is written
+// into an image which is then uploaded to the text model texture.
+type textLabel struct {
+	model   *vu.Entity   // 2D text model.
+	img     *image.NRGBA // the text image update texture.
+	density int          // text pixel density.
+	lines   int          // maximum number of text lines.
+}
+
+// newTextLabel creates a text model with a texture sized to fit the
+// given number of characters per line and lines of text.
+// The name must be unique for each label and density.
+func newTextLabel(eng *vu.Engine, ui *vu.Entity, name string, cols, lines, density int) *textLabel {
+	tl := &textLabel{density: density, lines: lines}
+	w, h := textLabelSize(cols, lines, density)
+	tl.img = image.NewNRGBA(image.Rect(0, 0, w, h))
+	tl.model = ui.AddModel("shd:tint", "msh:icon", "fnt:"+textFont(density))
+	tl.model.AddUpdatableTexture(eng, fmt.Sprintf("%s%d", name, density), tl.img)
+	tl.model.SetColor(0, 0, 0, 1).SetLayer(3)
+	return tl
+}
+
+// textLabelSize returns the texture pixel dimensions needed for the
+// given number of characters per line and lines of text.
+func textLabelSize(cols, lines, density int) (w, h int) {
+	return cols * txtCharWidth * density, lines * txtLineHeight * density
+}
+
+// write replaces the label text with the given lines of text.
+// Lines that don't fit are ignored. Returns an error if the
+// font is not yet loaded.
+func (tl *textLabel) write(eng *vu.Engine, lines ...string) (err error) {
+	draw.Draw(tl.img, tl.img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+	for i, line := range lines[:min(len(lines), tl.lines)] {
+		if line == "" {
+			continue
+		}
+		yoff := i * txtLineHeight * tl.density
+		if err = tl.model.WriteImageText(textFont(tl.density), line, 0, yoff, tl.img); err != nil {
+			return err
+		}
+	}
+	tl.model.UpdateTexture(eng, tl.img)
+	return nil
+}
+
+// place centers the label at the given pixel location where
+// lineHeight is the pixel height of one line of text.
+func (tl *textLabel) place(x, y, lineHeight float64) {
+	size := tl.img.Bounds().Size()
+	h := lineHeight * float64(tl.lines)
+	w := h * float64(size.X) / float64(size.Y)
+	tl.model.SetAt(x, y, 0).SetScale(w, h, 0)
+}
+
+// show or hide the label.
+func (tl *textLabel) show(visible bool) { tl.model.Cull(!visible) }
+
+// dispose removes the label model.
+func (tl *textLabel) dispose(eng *vu.Engine) { tl.model.Dispose(eng) }
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+// tutorial.go guides a new player through the basic moves.
+
+// tutorialSeed is the fixed deal used by the tutorial.
+const tutorialSeed uint = 1
+
+// tutorialStep is one guided move: the card to pick, where to place it,
+// and the hint shown to the player.
+type tutorialStep struct {
+	pick  uint   // card to select.
+	place uint   // card or empty pile to place the selected card on.
+	hint  string // short instruction for the player.
+}
+
+// tutorialSteps play the start of the tutorial deal, covering freecells,
+// foundations, and building a cascade sequence. Auto moves are off
+// during the tutorial so the player makes every move.
+var tutorialSteps = []tutorialStep{
+	{D3, EMPTY_PILE1, "tap 3D, then a freecell"},
+	{C2, EMPTY_PILE1 + 1, "park 2C in a freecell"},
+	{AC, EMPTY_PILE1 + FC, "send AC to its foundation"},
+	{C2, AC, "build 2C onto AC"},
+	{AS, EMPTY_PILE1 + FS, "send AS to its foundation"},
+	{C8, EMPTY_PILE1 + 1, "park 8C in a freecell"},
+	{JH, QC, "build JH down onto QC"},
+}
+
+// tutorial gates the player picks so that only the move for the
+// current step can be made.
+type tutorial struct {
+	steps []tutorialStep
+	step  int // current step.
+}
+
+// newTutorial starts the tutorial at the first step.
+func newTutorial() *tutorial {
+	return &tutorial{steps: tutorialSteps}
+}
+
+// done returns true once all the steps have been played.
+func (t *tutorial) done() bool { return t.step >= len(t.steps) }
+
+// hint returns the instruction for the current step.
+func (t *tutorial) hint() string {
+	if t.done() {
+		return "tutorial done, have fun!"
+	}
+	return t.steps[t.step].hint
+}
+
+// expected returns the pick needed for the current step: the step card
+// until it is selected, and then where it is placed.
+// Returns NO_HIT when the tutorial is done.
+func (t *tutorial) expected(l *logic) uint {
+	if t.done() {
+		return NO_HIT
+	}
+	step := t.steps[t.step]
+	if l.selected == step.pick {
+		return step.place
+	}
+	return step.pick
+}
+
+// interact plays the pick if it is the expected pick, advancing to the
+// next step once the step move is made. Returns false if the pick was
+// rejected, and true if it was played.
+func (t *tutorial) interact(l *logic, pick uint) (allowed, moved bool) {
+	if t.done() {
+		return true, l.Interact(pick)
+	}
+	if pick != t.expected(l) {
+		return false, false
+	}
+	if moved = l.Interact(pick); moved {
+		t.step++
+	}
+	return true, moved
+}
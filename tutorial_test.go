@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText : © 2025 Galvanized Logic Inc.
+// SPDX-License-Identifier: BSD-2-Clause
+
+package main
+
+import "testing"
+
+// go test -run Tutorial
+func TestTutorial(t *testing.T) {
+	l := &logic{}
+	l.NewGame(tutorialSeed)
+	tut := newTutorial()
+	for i, step := range tut.steps {
+		// wrong picks are rejected without changing the game.
+		before := l.Board()
+		for _, wrong := range []uint{KS, EMPTY_PILE16, step.place} {
+			if allowed, _ := tut.interact(l, wrong); allowed || l.Board() != before || l.isSelectionActive() {
+				t.Fatalf("step %d: expected pick %d to be rejected", i, wrong)
+			}
+		}
+
+		// the step card is selected and then placed.
+		if allowed, moved := tut.interact(l, step.pick); !allowed || moved || l.selected != step.pick {
+			t.Fatalf("step %d: expected %s to be selected", i, getCard(step.pick).Sym)
+		}
+		if allowed, _ := tut.interact(l, EMPTY_PILE16); allowed || l.selected != step.pick {
+			t.Fatalf("step %d: expected a wrong placement to be rejected", i)
+		}
+		if allowed, moved := tut.interact(l, step.place); !allowed || !moved || tut.step != i+1 {
+			t.Fatalf("step %d: expected %q to advance", i, step.hint)
+		}
+	}
+	if !tut.done() || tut.expected(l) != NO_HIT {
+		t.Errorf("expected the tutorial to be done")
+	}
+
+	// the tutorial is only for new players.
+	s := newSave(t.TempDir(), "freecell.save")
+	if !s.firstRun() {
+		t.Errorf("expected a first run")
+	}
+	s.recordLoss(42)
+	if s.firstRun() {
+		t.Errorf("expected players with attempts to skip the tutorial")
+	}
+}